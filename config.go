@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/juliusmh/gherkin-fmt/format"
+)
+
+const configFile = ".gherkinfmt"
+
+// formatFlags registers the flags controlling the formatted output on fs.
+// The same names are used as keys in .gherkinfmt files.
+func formatFlags(fs *flag.FlagSet, cfg *format.Config) {
+	d := format.DefaultConfig()
+	fs.IntVar(&cfg.Indent, "indent", d.Indent, "amount of whitespaces for indentation, at least 1")
+	fs.BoolVar(&cfg.UseTabs, "use-tabs", d.UseTabs, "indent with tabs instead of spaces")
+	fs.StringVar(&cfg.Align, "align", d.Align, "align tables left|right|center|auto|none")
+	fs.StringVar(&cfg.TableStyle, "table-style", d.TableStyle, "table style gherkin|markdown")
+	cfg.CellPadding = d.CellPadding
+	fs.Var(count{&cfg.CellPadding}, "cell-padding", "`spaces` between table pipes and cell content")
+	fs.BoolVar(&cfg.SortExamples, "sort-examples", d.SortExamples, "sort the rows of examples tables, comparing cells as strings")
+	fs.IntVar(&cfg.SortExamplesColumn, "sort-examples-column", d.SortExamplesColumn, "column to sort examples by with -sort-examples, counting from 0")
+	fs.BoolVar(&cfg.AlignKeywords, "align-keywords", d.AlignKeywords, "right-align step keywords within each scenario")
+	fs.BoolVar(&cfg.IndentContinuations, "indent-continuations", d.IndentContinuations, "align And, But and * keywords with the step they continue")
+	fs.StringVar(&cfg.TagWrap, "tag-wrap", d.TagWrap, "tag layout inline|one-per-line|width")
+	fs.IntVar(&cfg.TagWidth, "tag-width", d.TagWidth, "maximum width of a tag line with -tag-wrap width")
+	fs.StringVar(&cfg.TagCase, "tag-case", d.TagCase, "tag case preserve|lower|upper")
+	fs.BoolVar(&cfg.SortTags, "sort-tags", d.SortTags, "sort tags alphabetically, ignoring case")
+	fs.StringVar(&cfg.LineEnding, "line-ending", d.LineEnding, "line endings lf|crlf|auto")
+	fs.BoolVar(&cfg.NormalizeUnicode, "normalize-unicode", d.NormalizeUnicode, "convert text to Unicode normalization form C")
+	fs.StringVar(&cfg.BOM, "bom", d.BOM, "byte order mark preserve|strip|add")
+	fs.IntVar(&cfg.MaxWidth, "max-width", d.MaxWidth, "wrap descriptions at this width and warn about longer steps and tables (0 disables)")
+	cfg.NoFinalNewline = d.NoFinalNewline
+	fs.Var(negated{&cfg.NoFinalNewline}, "final-newline", "end the output with a newline")
+	cfg.BlankLines = d.BlankLines
+	fs.Var(count{&cfg.BlankLines}, "blank-lines", "number of blank `lines` between scenarios")
+	fs.BoolVar(&cfg.ReformatJSON, "reformat-json", d.ReformatJSON, "pretty-print JSON docstrings")
+	fs.BoolVar(&cfg.TrimNames, "trim-names", d.TrimNames, "drop a trailing period from feature, rule, background, scenario and examples names")
+	fs.BoolVar(&cfg.PreserveKeywords, "preserve-keywords", d.PreserveKeywords, "keep keyword synonyms such as Ability or Scenario Template instead of the canonical keyword")
+	fs.StringVar(&cfg.DocStringDelimiter, "docstring-delimiter", d.DocStringDelimiter, "docstring delimiter preserve|quote|backtick")
+	fs.BoolVar(&cfg.ReformatXML, "reformat-xml", d.ReformatXML, "pretty-print XML docstrings")
+	fs.IntVar(&cfg.DocStringIndent, "docstring-indent", d.DocStringIndent, "indentation of reformatted JSON and XML docstrings (default -indent)")
+	fs.BoolVar(&cfg.MultipleFeatures, "multiple-features", d.MultipleFeatures, "accept files with several features, each formatted on its own")
+}
+
+// count is a flag for a format.Config count whose zero value means one.
+// A count of zero given on the command line is stored as -1, which
+// format.Config reads as none.
+type count struct{ n *int }
+
+func (c count) String() string {
+	if c.n == nil || *c.n < 0 {
+		return "0"
+	}
+	return strconv.Itoa(*c.n)
+}
+
+func (c count) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("must not be negative, got %d", n)
+	}
+	if n == 0 {
+		n = -1
+	}
+	*c.n = n
+	return nil
+}
+
+// negated is a boolean flag setting a format.Config field named for the
+// opposite, such as -final-newline for NoFinalNewline.
+type negated struct{ b *bool }
+
+func (v negated) IsBoolFlag() bool { return true }
+
+func (v negated) String() string {
+	return strconv.FormatBool(v.b != nil && !*v.b)
+}
+
+func (v negated) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v.b = !b
+	return nil
+}
+
+// checkAlign resets an unknown alignment of cfg to left with a warning
+// naming source.
+func checkAlign(cfg *format.Config, source string) {
+	switch cfg.Align {
+	case "left", "right", "center", "auto", "none":
+	default:
+		fmt.Fprintf(os.Stderr, "%s: unknown alignment %q, using left\n", source, cfg.Align)
+		cfg.Align = "left"
+	}
+}
+
+// checkIndent rejects indentations that would flatten the document.
+func checkIndent(cfg *format.Config) error {
+	if cfg.Indent < 1 {
+		return fmt.Errorf("indent must be at least 1, got %d", cfg.Indent)
+	}
+	if cfg.DocStringIndent < 0 {
+		return fmt.Errorf("docstring-indent must not be negative, got %d", cfg.DocStringIndent)
+	}
+	return nil
+}
+
+// configLoader resolves the format configuration of a file from the nearest
+// .gherkinfmt file in its directory or above. Flags given on the command
+// line override values from the file, which override the defaults.
+type configLoader struct {
+	cli  format.Config
+	mu   sync.Mutex
+	dirs map[string]*format.Config
+}
+
+func newConfigLoader(cli format.Config) *configLoader {
+	return &configLoader{
+		cli:  cli,
+		dirs: make(map[string]*format.Config),
+	}
+}
+
+func (l *configLoader) load(file string) (*format.Config, error) {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dir(dir)
+}
+
+func (l *configLoader) dir(dir string) (*format.Config, error) {
+	if cfg, ok := l.dirs[dir]; ok {
+		return cfg, nil
+	}
+	name := filepath.Join(dir, configFile)
+	src, err := ioutil.ReadFile(name)
+	var cfg *format.Config
+	switch {
+	case err == nil:
+		if cfg, err = parseConfig(src); err != nil {
+			return nil, fmt.Errorf("%s:%v", name, err)
+		}
+		cfg.Dialects = l.cli.Dialects
+		cfg.Header = l.cli.Header
+		if err := checkIndent(cfg); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		checkAlign(cfg, name)
+	case os.IsNotExist(err):
+		if parent := filepath.Dir(dir); parent != dir {
+			if cfg, err = l.dir(parent); err != nil {
+				return nil, err
+			}
+		} else {
+			cfg = &l.cli
+		}
+	default:
+		return nil, err
+	}
+	l.dirs[dir] = cfg
+	return cfg, nil
+}
+
+// parseConfig reads key=value lines, where the keys are format flag names.
+// Empty lines and lines starting with # are ignored.
+func parseConfig(src []byte) (*format.Config, error) {
+	var cfg format.Config
+	fs := flag.NewFlagSet(configFile, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	formatFlags(fs, &cfg)
+
+	s := bufio.NewScanner(bytes.NewReader(src))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%d: expected key=value, got %q", n, line)
+		}
+		if err := fs.Set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])); err != nil {
+			return nil, fmt.Errorf("%d: %v", n, err)
+		}
+	}
+
+	var err error
+	flag.Visit(func(f *flag.Flag) {
+		if fs.Lookup(f.Name) != nil && err == nil {
+			err = fs.Set(f.Name, f.Value.String())
+		}
+	})
+	return &cfg, err
+}
@@ -0,0 +1,138 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	gherkin "github.com/cucumber/gherkin/go/v26"
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+// keywordKinds are the keyword types of a gherkin dialect. Step keywords
+// are matched with a trailing space.
+var keywordKinds = map[string]bool{
+	"feature":         false,
+	"rule":            false,
+	"background":      false,
+	"scenario":        false,
+	"scenarioOutline": false,
+	"examples":        false,
+	"given":           true,
+	"when":            true,
+	"then":            true,
+	"and":             true,
+	"but":             true,
+}
+
+// stepKeywordTypes are the types of the step keywords of each kind.
+var stepKeywordTypes = map[string]messages.StepKeywordType{
+	"given": messages.StepKeywordType_CONTEXT,
+	"when":  messages.StepKeywordType_ACTION,
+	"then":  messages.StepKeywordType_OUTCOME,
+	"and":   messages.StepKeywordType_CONJUNCTION,
+	"but":   messages.StepKeywordType_CONJUNCTION,
+}
+
+type dialects map[string]*gherkin.Dialect
+
+func (d dialects) GetDialect(language string) *gherkin.Dialect {
+	if dialect, ok := d[language]; ok {
+		return dialect
+	}
+	return gherkin.DialectsBuiltin().GetDialect(language)
+}
+
+// builtin are the built-in dialects with the keywords of gherkin5Keywords
+// moved to the front.
+var builtin = builtinDialects()
+
+func builtinDialects() dialects {
+	d := make(dialects)
+	for language, kinds := range gherkin5Keywords {
+		dialect := copyDialect(gherkin.DialectsBuiltin().GetDialect(language))
+		for kind, first := range kinds {
+			keywords := []string{first}
+			for _, keyword := range dialect.Keywords[kind] {
+				if keyword != first {
+					keywords = append(keywords, keyword)
+				}
+			}
+			dialect.Keywords[kind] = keywords
+		}
+		d[language] = dialect
+	}
+	return d
+}
+
+// copyDialect returns a copy of dialect whose keyword maps can be changed.
+func copyDialect(dialect *gherkin.Dialect) *gherkin.Dialect {
+	c := *dialect
+	c.Keywords = make(map[string][]string, len(dialect.Keywords))
+	for kind, keywords := range dialect.Keywords {
+		c.Keywords[kind] = keywords
+	}
+	c.KeywordTypes = make(map[string]messages.StepKeywordType, len(dialect.KeywordTypes))
+	for keyword, typ := range dialect.KeywordTypes {
+		c.KeywordTypes[keyword] = typ
+	}
+	return &c
+}
+
+// ReadDialects reads keyword overrides from r and layers them over the
+// built-in dialects. The input maps languages to keyword types to lists
+// of keywords, as in
+//
+//	{"en": {"scenario": ["Example", "Scenario"], "given": ["Given", "Assuming"]}}
+//
+// Keyword types missing from the input keep their built-in keywords. The
+// first keyword of a feature, rule, background, scenario, outline or
+// examples type is the one the formatter writes. Step keywords are written
+// as in the source.
+func ReadDialects(r io.Reader) (gherkin.DialectProvider, error) {
+	var overrides map[string]map[string][]string
+	if err := json.NewDecoder(r).Decode(&overrides); err != nil {
+		return nil, err
+	}
+	d := make(dialects)
+	for language, kinds := range overrides {
+		base := builtin.GetDialect(language)
+		if base == nil {
+			return nil, fmt.Errorf("unknown language %q", language)
+		}
+		dialect := copyDialect(base)
+		for kind, keywords := range kinds {
+			step, ok := keywordKinds[kind]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown keyword type %q", language, kind)
+			}
+			if len(keywords) == 0 {
+				return nil, fmt.Errorf("%s: no keywords for %q", language, kind)
+			}
+			list := make([]string, len(keywords))
+			for i, keyword := range keywords {
+				keyword = strings.TrimSpace(keyword)
+				if keyword == "" {
+					return nil, fmt.Errorf("%s: empty keyword for %q", language, kind)
+				}
+				if step {
+					keyword += " "
+					if keyword != "* " {
+						dialect.KeywordTypes[keyword] = stepKeywordTypes[kind]
+					}
+				}
+				list[i] = keyword
+			}
+			dialect.Keywords[kind] = list
+		}
+		d[language] = dialect
+	}
+	// languages without overrides keep the keywords of builtin
+	for language, dialect := range builtin {
+		if _, ok := d[language]; !ok {
+			d[language] = dialect
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,177 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+// delimiter returns the delimiter a docstring was written with. A
+// docstring without one, as a Transform may add, uses """.
+func (p *printer) delimiter(v *messages.DocString) string {
+	if v.Delimiter == "```" {
+		return v.Delimiter
+	}
+	return "\"\"\""
+}
+
+// escapeDocString makes sure no line of content closes the docstring early.
+// Leading delimiters are escaped as \"\"\" or \`\`\`, which the parser
+// undoes.
+func escapeDocString(delimiter, content string) string {
+	escaped := `\"\"\"`
+	if delimiter == "```" {
+		escaped = "\\`\\`\\`"
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, delimiter) {
+			lines[i] = line[:len(line)-len(trimmed)] + escaped + trimmed[len(delimiter):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rawDirective is a comment that keeps the content of the docstring below
+// it from being reformatted.
+const rawDirective = "gherkin-fmt: raw"
+
+// raw reports whether the line above the docstring v is a raw directive.
+func (p *printer) raw(v *messages.DocString) bool {
+	for _, c := range p.comments {
+		if c.Location.Line == v.Location.Line-1 {
+			return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(c.Text), "#")) == rawDirective
+		}
+	}
+	return false
+}
+
+func (p *printer) docString(v *messages.DocString) {
+	raw := p.raw(v)
+	p.writeComments(2, int(v.Location.Line))
+	contentType := strings.TrimSpace(v.MediaType)
+	content := v.Content
+	if !raw && p.cfg.ReformatJSON && (contentType == "" || strings.Contains(contentType, "json")) {
+		content = reformatJSON(content, strings.Repeat(" ", p.cfg.DocStringIndent))
+	}
+	if !raw && p.cfg.ReformatXML && strings.Contains(contentType, "xml") {
+		content = reformatXML(content, strings.Repeat(" ", p.cfg.DocStringIndent))
+	}
+	delimiter := p.delimiter(v)
+	switch p.cfg.DocStringDelimiter {
+	case "quote":
+		delimiter = "\"\"\""
+	case "backtick":
+		delimiter = "```"
+	}
+	content = escapeDocString(delimiter, content)
+
+	p.write(2, "%s%s", delimiter, contentType)
+	p.writeVerbatim(2, content)
+	p.write(2, delimiter)
+}
+
+// reformatJSON pretty-prints content if it is valid JSON and returns it
+// unchanged otherwise. Only the whitespace between tokens changes, so key
+// order, numbers and string escapes are kept as written.
+func reformatJSON(content, indent string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(content), "", indent); err != nil {
+		return content
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// reformatXML pretty-prints content if it is well-formed XML and returns
+// it unchanged otherwise. Elements holding only text stay on one line;
+// whitespace between elements is replaced by the indentation.
+func reformatXML(content, indent string) string {
+	d := xml.NewDecoder(strings.NewReader(content))
+	var b strings.Builder
+	depth := 0
+	var last xml.Token
+	newline := func() {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.Repeat(indent, depth))
+	}
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return content
+		}
+		switch v := t.(type) {
+		case xml.StartElement:
+			newline()
+			b.WriteString("<" + rawName(v.Name))
+			for _, attr := range v.Attr {
+				b.WriteString(" " + rawName(attr.Name) + `="` + escapeXML(attr.Value, true) + `"`)
+			}
+			b.WriteString(">")
+			depth++
+		case xml.EndElement:
+			depth--
+			switch last.(type) {
+			case xml.StartElement:
+				s := strings.TrimSuffix(b.String(), ">")
+				b.Reset()
+				b.WriteString(s + "/>")
+			case xml.CharData:
+				b.WriteString("</" + rawName(v.Name) + ">")
+			default:
+				newline()
+				b.WriteString("</" + rawName(v.Name) + ">")
+			}
+		case xml.CharData:
+			text := strings.TrimSpace(string(v))
+			if text == "" {
+				continue
+			}
+			if _, ok := last.(xml.StartElement); !ok {
+				newline()
+			}
+			b.WriteString(escapeXML(text, false))
+		case xml.Comment:
+			newline()
+			b.WriteString("<!--" + string(v) + "-->")
+		case xml.ProcInst:
+			newline()
+			b.WriteString("<?" + v.Target + " " + string(v.Inst) + "?>")
+		case xml.Directive:
+			newline()
+			b.WriteString("<!" + string(v) + ">")
+		}
+		last = xml.CopyToken(t)
+	}
+	if depth != 0 || b.Len() == 0 {
+		return content
+	}
+	return b.String()
+}
+
+// rawName returns name with its namespace prefix as written.
+func rawName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// escapeXML escapes the characters of s that are special in text, or in
+// a double-quoted attribute value if attr is set.
+func escapeXML(s string, attr bool) string {
+	s = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+	if attr {
+		s = strings.Replace(s, `"`, "&quot;", -1)
+	}
+	return s
+}
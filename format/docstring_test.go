@@ -0,0 +1,159 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+// step is a formatted scenario with a single step, for the tests to add
+// a docstring to.
+const step = "Feature: a\n\n  Scenario: b\n    Given c\n"
+
+func TestDocStringIndent(t *testing.T) {
+	src := step + "    \"\"\"json\n    {\"a\": {\"b\": [1, 2]}}\n    \"\"\"\n"
+	for _, tt := range []struct {
+		indent int
+		want   string
+	}{
+		{0, "{\n  \"a\": {\n    \"b\": [\n      1,\n      2\n    ]\n  }\n}"},
+		{4, "{\n    \"a\": {\n        \"b\": [\n            1,\n            2\n        ]\n    }\n}"},
+	} {
+		cfg := DefaultConfig()
+		cfg.ReformatJSON = true
+		cfg.DocStringIndent = tt.indent
+		want := step + "    \"\"\"json\n" + indentLines("    ", tt.want) + "\n    \"\"\"\n"
+		if out := formatString(t, src, cfg); out != want {
+			t.Errorf("docstring indent %d: got:\n%s\nwant:\n%s", tt.indent, out, want)
+		}
+	}
+}
+
+// formatJSON formats a step with a one-line JSON docstring holding doc,
+// with ReformatJSON on, and returns the docstring content unindented.
+func formatJSON(t *testing.T, doc string) string {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.ReformatJSON = true
+	out := formatString(t, step+"    \"\"\"json\n    "+doc+"\n    \"\"\"\n", cfg)
+	content := strings.TrimPrefix(out, step+"    \"\"\"json\n")
+	content = strings.TrimSuffix(content, "\n    \"\"\"\n")
+	return strings.Replace(strings.TrimPrefix(content, "    "), "\n    ", "\n", -1)
+}
+
+// TestReformatJSONNumbers checks that numbers are written as in the
+// source, without a round trip through float64.
+func TestReformatJSONNumbers(t *testing.T) {
+	got := formatJSON(t, `{"id": 10000000000000001, "pi": 3.14159265358979323846264338327950288, "big": 1e400, "zero": -0.0}`)
+	want := `{
+  "id": 10000000000000001,
+  "pi": 3.14159265358979323846264338327950288,
+  "big": 1e400,
+  "zero": -0.0
+}`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReformatJSONKeyOrder(t *testing.T) {
+	got := formatJSON(t, `{"zeta": 1, "alpha": {"y": 2, "b": 3}, "mid": [{"k": 4, "a": 5}]}`)
+	want := `{
+  "zeta": 1,
+  "alpha": {
+    "y": 2,
+    "b": 3
+  },
+  "mid": [
+    {
+      "k": 4,
+      "a": 5
+    }
+  ]
+}`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDocStringDelimiter(t *testing.T) {
+	for _, tt := range []struct {
+		delimiter, src, want string
+	}{
+		{"backtick", "\"\"\"json\n{}\n\"\"\"", "```json\n{}\n```"},
+		{"quote", "```json\n{}\n```", "\"\"\"json\n{}\n\"\"\""},
+		{"preserve", "```json\n{}\n```", "```json\n{}\n```"},
+		// the body holds the new delimiter, which has to be escaped
+		{"quote", "```\nx\n\"\"\"\n```", "\"\"\"\nx\n\\\"\\\"\\\"\n\"\"\""},
+		{"backtick", "\"\"\"\nx\n```\n\"\"\"", "```\nx\n\\`\\`\\`\n```"},
+	} {
+		cfg := DefaultConfig()
+		cfg.DocStringDelimiter = tt.delimiter
+		want := step + indentLines("    ", tt.want) + "\n"
+		out := formatString(t, step+indentLines("      ", tt.src)+"\n", cfg)
+		if out != want {
+			t.Errorf("%s: %q got:\n%s\nwant:\n%s", tt.delimiter, tt.src, out, want)
+		}
+		if again := formatString(t, out, cfg); again != out {
+			t.Errorf("%s: formatting again changed the output:\n%s", tt.delimiter, again)
+		}
+	}
+}
+
+func TestRawDirective(t *testing.T) {
+	src := step + `    # gherkin-fmt: raw
+    """json
+    {"kept": [1, 2]}
+    """
+    When d
+    """json
+    {"reformatted": [1, 2]}
+    """
+`
+	want := step + `    # gherkin-fmt: raw
+    """json
+    {"kept": [1, 2]}
+    """
+    When d
+    """json
+    {
+      "reformatted": [
+        1,
+        2
+      ]
+    }
+    """
+`
+	cfg := DefaultConfig()
+	cfg.ReformatJSON = true
+	if out := formatString(t, src, cfg); out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestReformatXML(t *testing.T) {
+	src := step + "    \"\"\"xml\n" +
+		`    <?xml version="1.0"?><order id="1" note="a &quot;b&quot; &amp; &lt;c&gt;"><!-- items --><item sku='x"y'/><name>A &amp; B</name><empty></empty></order>` +
+		"\n    \"\"\"\n"
+	want := `<?xml version="1.0"?>
+<order id="1" note="a &quot;b&quot; &amp; &lt;c&gt;">
+  <!-- items -->
+  <item sku="x&quot;y"/>
+  <name>A &amp; B</name>
+  <empty/>
+</order>`
+	cfg := DefaultConfig()
+	cfg.ReformatXML = true
+	want = step + "    \"\"\"xml\n" + indentLines("    ", want) + "\n    \"\"\"\n"
+	out := formatString(t, src, cfg)
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	if again := formatString(t, out, cfg); again != out {
+		t.Errorf("formatting again changed the output:\n%s", again)
+	}
+}
+
+// indentLines puts indent in front of every line of s.
+func indentLines(indent, s string) string {
+	return indent + strings.Replace(s, "\n", "\n"+indent, -1)
+}
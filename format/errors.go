@@ -0,0 +1,83 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseError is a syntax error at a position in the source document.
+type ParseError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParseErrors holds all syntax errors found in a document. It unwraps to
+// its first error, so errors.As with a *ParseError finds that one; use
+// ParseErrors itself to see all of them.
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e ParseErrors) Unwrap() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e[0]
+}
+
+// ErrUnsupported matches errors for parts of a document the formatter
+// cannot write, see UnsupportedError.
+var ErrUnsupported = errors.New("unsupported construct")
+
+// UnsupportedError is returned for a node of the parsed document that the
+// formatter does not know how to write. It matches ErrUnsupported with
+// errors.Is.
+type UnsupportedError struct {
+	// Node is the node that was not written.
+	Node interface{}
+	msg  string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s: %T", e.msg, e.Node)
+}
+
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+var parseErrorPattern = regexp.MustCompile(`^\((\d+):(\d+)\): (.*)$`)
+
+// parseErrors converts an error from the gherkin parser into ParseErrors.
+// The parser does not export its error types, so the positions are read
+// back from the messages.
+func parseErrors(err error) error {
+	var errs ParseErrors
+	for _, line := range strings.Split(err.Error(), "\n") {
+		m := parseErrorPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		l, _ := strconv.Atoi(m[1])
+		c, _ := strconv.Atoi(m[2])
+		errs = append(errs, &ParseError{Line: l, Column: c, Msg: m[3]})
+	}
+	if len(errs) == 0 {
+		return err
+	}
+	return errs
+}
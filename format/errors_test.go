@@ -0,0 +1,53 @@
+package format
+
+import (
+	"errors"
+	"testing"
+
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+func TestParseErrorContract(t *testing.T) {
+	_, err := FormatBytes([]byte("Given a\nFeature: b\n  Then c\n"), DefaultConfig())
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("errors.As(%v, *ParseErrors) is false", err)
+	}
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("errors.As(%v, **ParseError) is false", err)
+	}
+	if pe != errs[0] {
+		t.Errorf("errors.As found %v, want the first error %v", pe, errs[0])
+	}
+	if pe.Line != 1 || pe.Column != 1 {
+		t.Errorf("first error at %d:%d, want 1:1", pe.Line, pe.Column)
+	}
+	if errors.Is(err, ErrUnsupported) {
+		t.Errorf("parse error %v matches ErrUnsupported", err)
+	}
+}
+
+func TestUnsupportedErrorContract(t *testing.T) {
+	cfg := DefaultConfig()
+	child := &messages.FeatureChild{}
+	cfg.Transform = func(doc *messages.GherkinDocument) error {
+		doc.Feature.Children = append(doc.Feature.Children, child)
+		return nil
+	}
+	_, err := FormatBytes([]byte("Feature: a\n"), cfg)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("errors.Is(%v, ErrUnsupported) is false", err)
+	}
+	var ue *UnsupportedError
+	if !errors.As(err, &ue) {
+		t.Fatalf("errors.As(%v, **UnsupportedError) is false", err)
+	}
+	if ue.Node != child {
+		t.Errorf("got node %#v, want the added child", ue.Node)
+	}
+	var errs ParseErrors
+	if errors.As(err, &errs) {
+		t.Errorf("unsupported construct %v matches ParseErrors", err)
+	}
+}
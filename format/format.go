@@ -0,0 +1,802 @@
+// Package format formats gherkin feature files.
+package format
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	gherkin "github.com/cucumber/gherkin/go/v26"
+	messages "github.com/cucumber/messages/go/v21"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Config controls the layout of the formatted document. The zero value
+// gives the same layout as DefaultConfig: unset fields mean the defaults of
+// the command line tool, and settings that differ from a default of true
+// or one are spelled out, as in NoFinalNewline.
+type Config struct {
+	// Indent is the number of spaces per nesting level.
+	Indent int
+	// Align is the alignment of table cells: "left", "right", "center",
+	// "auto", which right-aligns numeric columns, or "none", which does
+	// not pad cells to a common width. Unknown values are treated as
+	// "left". In markdown tables, the colons of an existing separator row
+	// set the alignment of their column instead.
+	Align string
+	// TagWrap is "inline", which writes all tags of a construct on one
+	// line, "one-per-line" or "width", which wraps tags after TagWidth
+	// columns.
+	TagWrap  string
+	TagWidth int
+	// TagCase is "preserve", "lower" or "upper". Arguments in parentheses,
+	// as in @jira(PROJ-1), keep their case.
+	TagCase string
+	// SortTags sorts the tags of each construct alphabetically, ignoring
+	// case. Otherwise tags keep their source order.
+	SortTags bool
+	// SortExamples sorts the body rows of examples tables by column
+	// SortExamplesColumn, counting from zero. The header stays first.
+	// Values compare as strings, so 10 sorts before 9.
+	SortExamples       bool
+	SortExamplesColumn int
+	// CellPadding is the number of spaces between the pipes of a table
+	// and the cell content. Zero means one space and a negative value no
+	// padding.
+	CellPadding int
+	// TableStyle is "gherkin" or "markdown", which adds a header separator
+	// row with alignment markers to every table.
+	TableStyle string
+	// AlignKeywords right-aligns step keywords within each scenario so
+	// that the step texts start in the same column.
+	AlignKeywords bool
+	// IndentContinuations right-aligns the keywords of And, But and *
+	// steps with the keyword of the step they continue.
+	IndentContinuations bool
+	// ReformatJSON pretty-prints docstrings holding valid JSON. Otherwise
+	// docstring content is kept as is.
+	ReformatJSON bool
+	// DocStringDelimiter is "preserve", "quote" for """ or "backtick"
+	// for ```.
+	DocStringDelimiter string
+	// ReformatXML pretty-prints docstrings with an xml content type
+	// holding well-formed XML.
+	ReformatXML bool
+	// DocStringIndent is the number of spaces per nesting level of
+	// reformatted JSON and XML. Zero means Indent.
+	DocStringIndent int
+	// PreserveKeywords keeps the keyword synonym the author used for
+	// features, backgrounds, scenarios and examples, such as "Ability" or
+	// "Scenario Template", instead of writing the canonical keyword.
+	PreserveKeywords bool
+	// TrimNames drops trailing periods from the names of features, rules,
+	// backgrounds, scenarios and examples, unless the name ends with an
+	// ellipsis. Surrounding whitespace is always dropped.
+	TrimNames bool
+	// BlankLines is the number of blank lines between backgrounds and
+	// scenarios. Zero means one and a negative value none.
+	BlankLines int
+	// UseTabs indents with one tab per level instead of Indent spaces.
+	// Table padding and reformatted docstrings still use spaces.
+	UseTabs bool
+	// LineEnding is one of "lf", "crlf" or "auto", which keeps the
+	// dominant line ending of the input.
+	LineEnding string
+	// NormalizeUnicode converts the document, docstrings included, to
+	// Unicode normalization form C, so that text typed on different
+	// systems compares equal.
+	NormalizeUnicode bool
+	// BOM is "preserve", which keeps a leading byte order mark of the
+	// input, "strip" or "add".
+	BOM string
+	// MaxWidth wraps description lines longer than MaxWidth columns at
+	// word boundaries. Steps and table rows are never wrapped, but
+	// reported to Warn if they are too wide. Zero means no limit.
+	MaxWidth int
+	// Warn, if set, is called with the source line and a message for
+	// problems that do not stop formatting.
+	Warn func(line int, msg string)
+	// Dialects provides the keywords of each language. Nil means the
+	// built-in gherkin dialects; see ReadDialects for custom keywords.
+	Dialects gherkin.DialectProvider
+	// NoFinalNewline ends the output with the last line of the document
+	// instead of a newline.
+	NoFinalNewline bool
+	// Transform, if set, is called with the parsed document before it is
+	// written and may change it in place. Comments are placed by the
+	// source line of the nodes, so added nodes should carry the Location
+	// of the node they replace or follow. An error stops formatting.
+	Transform func(*messages.GherkinDocument) error
+	// MultipleFeatures accepts documents with several features, which
+	// gherkin does not allow. Each feature is parsed on its own, Transform
+	// is called for each, and they are written BlankLines apart.
+	MultipleFeatures bool
+	// Header is written at the top of the output, above the language
+	// line, followed by a blank line. Its lines must be comments or blank.
+	// A document whose first comments are the header is not given a
+	// second copy.
+	Header string
+}
+
+// bom is the UTF-8 byte order mark.
+var bom = []byte("\xef\xbb\xbf")
+
+// Parse parses a gherkin document from r. A leading byte order mark is
+// ignored. Syntax errors are reported as ParseErrors.
+func Parse(r io.Reader) (*messages.GherkinDocument, error) {
+	return ParseWithDialects(r, nil)
+}
+
+// ParseWithDialects is like Parse but recognizes the keywords of dialects,
+// such as those returned by ReadDialects. A nil dialects means the built-in
+// dialects.
+func ParseWithDialects(r io.Reader, dialects gherkin.DialectProvider) (*messages.GherkinDocument, error) {
+	if dialects == nil {
+		dialects = builtin
+	}
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	builder := gherkin.NewAstBuilder((&messages.Incrementing{}).NewId)
+	parser := gherkin.NewParser(builder)
+	parser.StopAtFirstError(false)
+	err = parser.Parse(gherkin.NewScanner(bytes.NewReader(bytes.TrimPrefix(src, bom))), gherkin.NewMatcher(dialects))
+	if err != nil {
+		return nil, parseErrors(err)
+	}
+	return builder.GetGherkinDocument(), nil
+}
+
+// ParseFeatures is like ParseWithDialects but accepts documents with
+// several features, as Config.MultipleFeatures does. It returns one
+// document per feature, with the comments above each feature.
+func ParseFeatures(r io.Reader, dialects gherkin.DialectProvider) ([]*messages.GherkinDocument, error) {
+	if dialects == nil {
+		dialects = builtin
+	}
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseSegments(splitFeatures(string(bytes.TrimPrefix(src, bom)), dialects), dialects)
+}
+
+// parseSegments parses each segment returned by splitFeatures. The syntax
+// errors of all segments are reported at once.
+func parseSegments(segments []string, dialects gherkin.DialectProvider) ([]*messages.GherkinDocument, error) {
+	var docs []*messages.GherkinDocument
+	var errs ParseErrors
+	for _, segment := range segments {
+		gherkinDocument, err := ParseWithDialects(strings.NewReader(segment), dialects)
+		if e, ok := err.(ParseErrors); ok && len(segments) > 1 {
+			errs = append(errs, e...)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, gherkinDocument)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return docs, nil
+}
+
+// DefaultConfig returns the configuration used by gherkin-fmt when no
+// options are given.
+func DefaultConfig() Config {
+	return Config{
+		Indent:             2,
+		Align:              "left",
+		TableStyle:         "gherkin",
+		TagWrap:            "inline",
+		TagWidth:           80,
+		TagCase:            "preserve",
+		BlankLines:         1,
+		CellPadding:        1,
+		LineEnding:         "auto",
+		BOM:                "preserve",
+		DocStringDelimiter: "preserve",
+	}
+}
+
+// withDefaults fills in the unset fields of c. It is applied once, as it
+// turns the negative counts meaning none into zero.
+func (c Config) withDefaults() Config {
+	if c.Indent < 1 {
+		c.Indent = 2
+	}
+	if c.DocStringIndent == 0 {
+		c.DocStringIndent = c.Indent
+	}
+	switch {
+	case c.CellPadding == 0:
+		c.CellPadding = 1
+	case c.CellPadding < 0:
+		c.CellPadding = 0
+	}
+	switch {
+	case c.BlankLines == 0:
+		c.BlankLines = 1
+	case c.BlankLines < 0:
+		c.BlankLines = 0
+	}
+	if c.Align == "" {
+		c.Align = "left"
+	}
+	if c.TagWrap == "" {
+		c.TagWrap = "inline"
+	}
+	if c.TagCase == "" {
+		c.TagCase = "preserve"
+	}
+	if c.TagWidth == 0 {
+		c.TagWidth = 80
+	}
+	if c.TableStyle == "" {
+		c.TableStyle = "gherkin"
+	}
+	if c.Dialects == nil {
+		c.Dialects = builtin
+	}
+	if c.DocStringDelimiter == "" {
+		c.DocStringDelimiter = "preserve"
+	}
+	if c.BOM == "" {
+		c.BOM = "preserve"
+	}
+	if c.LineEnding == "" {
+		c.LineEnding = "auto"
+	}
+	return c
+}
+
+// Format parses a gherkin document from r and writes its formatted form to w.
+// The output is written one scenario, or a few hundred table rows, at a
+// time, so w may have received part of the document when an error is
+// returned. The input and its parsed form are held in memory, which is most
+// of what formatting allocates. Syntax errors are returned as ParseErrors
+// and nodes that cannot be written as an UnsupportedError.
+func Format(r io.Reader, w io.Writer, cfg Config) error {
+	return FormatContext(context.Background(), r, w, cfg)
+}
+
+// FormatContext is like Format but stops with the error of ctx once it is
+// done. It is checked between scenarios and between table rows.
+func FormatContext(ctx context.Context, r io.Reader, w io.Writer, cfg Config) error {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	cfg = cfg.withDefaults()
+	hasBOM := bytes.HasPrefix(src, bom)
+	src = bytes.TrimPrefix(src, bom)
+	if cfg.NormalizeUnicode {
+		src = norm.NFC.Bytes(src)
+	}
+	segments := []string{string(src)}
+	if cfg.MultipleFeatures {
+		segments = splitFeatures(string(src), cfg.Dialects)
+	}
+	docs, err := parseSegments(segments, cfg.Dialects)
+	if err != nil {
+		return err
+	}
+	if cfg.Transform != nil {
+		for _, gherkinDocument := range docs {
+			if err := cfg.Transform(gherkinDocument); err != nil {
+				return err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p := &printer{
+		ctx:  ctx,
+		cfg:  cfg,
+		w:    w,
+		bom:  cfg.BOM == "add" || (cfg.BOM == "preserve" && hasBOM),
+		crlf: useCRLF(cfg.LineEnding, src),
+	}
+	if cfg.Header != "" {
+		p.header(docs[0])
+	}
+	for i, gherkinDocument := range docs {
+		if i > 0 {
+			for j := 0; j < p.cfg.BlankLines; j++ {
+				p.write(0, "")
+			}
+		}
+		p.comments = gherkinDocument.Comments
+		if gherkinDocument.Feature != nil {
+			if err := p.feature(gherkinDocument.Feature); err != nil {
+				return err
+			}
+		}
+		// comments after the last scenario, or all of them in a document
+		// without a feature
+		p.writeComments(0, math.MaxInt32)
+	}
+	return p.flush(true)
+}
+
+// FormatBytes formats the gherkin document src and returns the result.
+func FormatBytes(src []byte, cfg Config) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Format(bytes.NewReader(src), &buf, cfg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// useCRLF reports whether the output should use CRLF line endings. With
+// lineEnding set to "auto" or left empty, the dominant line ending of src
+// is kept.
+func useCRLF(lineEnding string, src []byte) bool {
+	switch lineEnding {
+	case "crlf":
+		return true
+	case "lf":
+		return false
+	}
+	crlf := bytes.Count(src, []byte("\r\n"))
+	return crlf > bytes.Count(src, []byte("\n"))-crlf
+}
+
+type printer struct {
+	ctx      context.Context
+	cfg      Config
+	w        io.Writer
+	bom      bool
+	crlf     bool
+	started  bool
+	blanks   int
+	result   bytes.Buffer
+	dialect  *gherkin.Dialect
+	comments []*messages.Comment
+	// depth is the number of levels the current scenario is nested below
+	// the feature, one inside a rule
+	depth int
+}
+
+// flush writes the buffered output to w. Leading whitespace of the document
+// is dropped, and trailing newlines are held back until the final flush,
+// where they are replaced by a single newline unless NoFinalNewline is set.
+func (p *printer) flush(final bool) error {
+	out := p.result.Bytes()
+	if !p.started {
+		out = bytes.TrimLeftFunc(out, unicode.IsSpace)
+	}
+	trimmed := bytes.TrimRight(out, "\n")
+	rest := append([]byte(nil), out[len(trimmed):]...)
+	if len(trimmed) > 0 {
+		if p.bom && !p.started {
+			trimmed = append(append([]byte(nil), bom...), trimmed...)
+		}
+		if p.crlf {
+			trimmed = bytes.Replace(trimmed, []byte("\n"), []byte("\r\n"), -1)
+		}
+		if _, err := p.w.Write(trimmed); err != nil {
+			return err
+		}
+		p.started = true
+	}
+	p.result.Reset()
+	if !final {
+		p.result.Write(rest)
+		return nil
+	}
+	if !p.cfg.NoFinalNewline && p.started {
+		newline := "\n"
+		if p.crlf {
+			newline = "\r\n"
+		}
+		_, err := io.WriteString(p.w, newline)
+		return err
+	}
+	return nil
+}
+
+// keyword returns the canonical keyword of the given type, such as
+// "feature" or "scenarioOutline", in the document's language.
+func (p *printer) keyword(kind string) string {
+	return p.dialect.Keywords[kind][0]
+}
+
+// nodeKeyword returns the keyword a node was written with if keywords are
+// preserved, and the canonical keyword of kind otherwise.
+func (p *printer) nodeKeyword(kind, keyword string) string {
+	if p.cfg.PreserveKeywords && keyword != "" {
+		return strings.TrimSpace(keyword)
+	}
+	return p.keyword(kind)
+}
+
+// header writes the configured header and drops its comments from the
+// start of doc if it is there already.
+func (p *printer) header(doc *messages.GherkinDocument) {
+	header := strings.Split(strings.TrimRight(p.cfg.Header, " \t\r\n"), "\n")
+	var lines []string
+	for _, line := range header {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(doc.Comments) >= len(lines) {
+		found := true
+		for i, line := range lines {
+			if strings.TrimSpace(doc.Comments[i].Text) != line {
+				found = false
+				break
+			}
+		}
+		if found {
+			doc.Comments = doc.Comments[len(lines):]
+		}
+	}
+	for _, line := range header {
+		p.write(0, "%s", strings.TrimSpace(line))
+	}
+	p.write(0, "")
+}
+
+// name returns the name of a node as it is written.
+func (p *printer) name(name string) string {
+	name = strings.TrimSpace(name)
+	if p.cfg.TrimNames && !strings.HasSuffix(name, "...") {
+		name = strings.TrimRight(name, ". \t")
+	}
+	return name
+}
+
+// continuation reports whether keyword is an And, But or * step keyword
+// of the document's language.
+func (p *printer) continuation(keyword string) bool {
+	for _, kind := range []string{"and", "but"} {
+		for _, k := range p.dialect.Keywords[kind] {
+			if strings.TrimSpace(k) == strings.TrimSpace(keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// indent returns the leading whitespace for the given nesting level.
+func (p *printer) indent(level int) string {
+	if p.cfg.UseTabs {
+		return strings.Repeat("\t", level+p.depth)
+	}
+	return strings.Repeat(" ", p.column(level))
+}
+
+// column returns the column text written at the given nesting level
+// starts at, counting indentation with spaces.
+func (p *printer) column(level int) int {
+	return (level + p.depth) * p.cfg.Indent
+}
+
+func (p *printer) write(indent int, f string, args ...interface{}) {
+	add := p.indent(indent)
+	lines := strings.Split(fmt.Sprintf(f, args...), "\n")
+	for _, line := range lines {
+		line = strings.TrimRightFunc(add+line, unicode.IsSpace)
+		if line == "" {
+			// collapse runs of blank lines, e.g. inside descriptions
+			if p.blanks >= max(1, p.cfg.BlankLines) {
+				continue
+			}
+			p.blanks++
+		} else {
+			p.blanks = 0
+		}
+		p.result.WriteString(line + "\n")
+	}
+}
+
+// writeVerbatim writes text with indentation added in front of every
+// non-empty line, leaving the rest of each line untouched. The parser only
+// strips spaces from docstring content, so with UseTabs the content is
+// indented with one space per tab of the enclosing delimiter.
+func (p *printer) writeVerbatim(indent int, text string) {
+	add := p.indent(indent)
+	if p.cfg.UseTabs {
+		add = strings.Repeat(" ", indent+p.depth)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" {
+			p.result.WriteString(add)
+		}
+		p.result.WriteString(line + "\n")
+	}
+	p.blanks = 0
+}
+
+// tagCase changes the case of tag up to its first parenthesis.
+func tagCase(tag, mode string) string {
+	name, args := tag, ""
+	if i := strings.IndexByte(tag, '('); i >= 0 {
+		name, args = tag[:i], tag[i:]
+	}
+	switch mode {
+	case "lower":
+		name = strings.ToLower(name)
+	case "upper":
+		name = strings.ToUpper(name)
+	}
+	return name + args
+}
+
+func (p *printer) writeTags(indent int, tags []*messages.Tag) {
+	if len(tags) == 0 {
+		return
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tagCase(tag.Name, p.cfg.TagCase)
+	}
+	if p.cfg.SortTags {
+		sort.SliceStable(names, func(i, j int) bool {
+			return strings.ToLower(names[i]) < strings.ToLower(names[j])
+		})
+	}
+	switch p.cfg.TagWrap {
+	case "one-per-line":
+		for _, name := range names {
+			p.write(indent, "%s", name)
+		}
+	case "width":
+		line := names[0]
+		for _, name := range names[1:] {
+			if p.column(indent)+displayWidth(line+" "+name) > p.cfg.TagWidth {
+				p.write(indent, "%s", line)
+				line = name
+				continue
+			}
+			line += " " + name
+		}
+		p.write(indent, "%s", line)
+	default:
+		p.write(indent, "%s", strings.Join(names, " "))
+	}
+}
+
+func (p *printer) writeComments(indent, line int) {
+	for len(p.comments) > 0 && p.comments[0].Location.Line < int64(line) {
+		p.write(indent, "%s", strings.TrimSpace(p.comments[0].Text))
+		p.comments = p.comments[1:]
+	}
+}
+
+// writeDescription writes a description at the given indent. Surrounding
+// blank lines are dropped; blank lines between paragraphs and the relative
+// indentation of the lines are kept.
+func (p *printer) writeDescription(indent int, description string) {
+	lines := strings.Split(description, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	common := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if common < 0 || n < common {
+			common = n
+		}
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			p.write(0, "")
+			continue
+		}
+		for _, line := range p.wrap(indent, line[common:]) {
+			p.write(indent, "%s", line)
+		}
+	}
+}
+
+func startLine(loc *messages.Location, tags []*messages.Tag) int {
+	if len(tags) > 0 {
+		return int(tags[0].Location.Line)
+	}
+	return int(loc.Line)
+}
+
+func (p *printer) feature(feature *messages.Feature) error {
+	p.dialect = p.cfg.Dialects.GetDialect(feature.Language)
+	if feature.Language != gherkin.DefaultDialect {
+		p.write(0, "# language: %s", feature.Language)
+	}
+	p.writeComments(0, startLine(feature.Location, feature.Tags))
+	p.writeTags(0, feature.Tags)
+	p.write(0, "%s: %s", p.nodeKeyword("feature", feature.Keyword), p.name(feature.Name))
+	p.writeDescription(1, feature.Description)
+	p.write(0, "")
+
+	for _, c := range feature.Children {
+		switch {
+		case c.Rule != nil:
+			if err := p.rule(c.Rule); err != nil {
+				return err
+			}
+		case c.Background != nil || c.Scenario != nil:
+			if err := p.child(c.Background, c.Scenario); err != nil {
+				return err
+			}
+		default:
+			return &UnsupportedError{Node: c, msg: "unhandled feature children"}
+		}
+	}
+	return nil
+}
+
+// rule writes a rule with its tags and description, and its background
+// and scenarios, which are nested one level deeper than those of the
+// feature.
+func (p *printer) rule(rule *messages.Rule) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	p.writeComments(1, startLine(rule.Location, rule.Tags))
+	p.writeTags(1, rule.Tags)
+	p.write(1, "%s: %s", p.nodeKeyword("rule", rule.Keyword), p.name(rule.Name))
+	p.writeDescription(2, rule.Description)
+	p.write(0, "")
+	p.depth++
+	defer func() { p.depth-- }()
+	for _, c := range rule.Children {
+		if c.Background == nil && c.Scenario == nil {
+			return &UnsupportedError{Node: c, msg: "unhandled rule children"}
+		}
+		if err := p.child(c.Background, c.Scenario); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// child writes a background or a scenario, whichever is set, followed by
+// the blank lines between children.
+func (p *printer) child(background *messages.Background, scenario *messages.Scenario) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	var steps []*messages.Step
+	var examples []*messages.Examples
+	switch {
+	case background != nil:
+		v := background
+		p.writeComments(1, int(v.Location.Line))
+		if v.Name != "" {
+			p.write(1, "%s: %s", p.nodeKeyword("background", v.Keyword), p.name(v.Name))
+		} else {
+			p.write(1, "%s:", p.nodeKeyword("background", v.Keyword))
+		}
+		p.writeDescription(2, v.Description)
+		steps = v.Steps
+	default:
+		v := scenario
+		p.writeComments(1, startLine(v.Location, v.Tags))
+		p.writeTags(1, v.Tags)
+		p.write(1, "%s: %s", p.nodeKeyword(p.scenarioKind(v), v.Keyword), p.name(v.Name))
+		p.writeDescription(2, v.Description)
+		steps = v.Steps
+		examples = v.Examples
+	}
+
+	width := 0
+	if p.cfg.AlignKeywords {
+		for _, step := range steps {
+			width = max(width, utf8.RuneCountInString(strings.TrimSpace(step.Keyword)))
+		}
+	}
+	parent := ""
+	for _, step := range steps {
+		w := width
+		if p.cfg.IndentContinuations {
+			if p.continuation(step.Keyword) {
+				w = max(w, utf8.RuneCountInString(parent))
+			} else {
+				parent = strings.TrimSpace(step.Keyword)
+			}
+		}
+		if err := p.step(step, w); err != nil {
+			return err
+		}
+	}
+
+	for _, ex := range examples {
+		p.write(0, "")
+		p.writeComments(2, startLine(ex.Location, ex.Tags))
+		p.writeTags(2, ex.Tags)
+		if ex.Name != "" {
+			p.write(2, "%s: %s", p.nodeKeyword("examples", ex.Keyword), p.name(ex.Name))
+		} else {
+			p.write(2, "%s:", p.nodeKeyword("examples", ex.Keyword))
+		}
+		p.writeDescription(3, ex.Description)
+		if ex.TableHeader == nil {
+			// examples without a table
+			continue
+		}
+		body := ex.TableBody
+		if p.cfg.SortExamples {
+			body = sortRows(body, p.cfg.SortExamplesColumn)
+		}
+		err := p.table(&messages.DataTable{
+			Rows: append([]*messages.TableRow{ex.TableHeader}, body...),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for i := 0; i < p.cfg.BlankLines; i++ {
+		p.write(0, "")
+	}
+	return p.flush(false)
+}
+
+// scenarioKind returns "scenarioOutline" if v was written with an outline
+// keyword and "scenario" otherwise. Gherkin 6 accepts examples below a
+// plain scenario, so the examples do not decide it.
+func (p *printer) scenarioKind(v *messages.Scenario) string {
+	for _, keyword := range p.dialect.ScenarioOutlineKeywords() {
+		if keyword == v.Keyword {
+			return "scenarioOutline"
+		}
+	}
+	return "scenario"
+}
+
+// sortRows returns a copy of rows sorted by the string values of column j.
+// Rows with equal values keep their order.
+func sortRows(rows []*messages.TableRow, j int) []*messages.TableRow {
+	sorted := append([]*messages.TableRow(nil), rows...)
+	if len(sorted) == 0 || j < 0 || j >= len(sorted[0].Cells) {
+		return sorted
+	}
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Cells[j].Value < sorted[b].Cells[j].Value
+	})
+	return sorted
+}
+
+// step writes a single step, padding its keyword to width.
+func (p *printer) step(step *messages.Step, width int) error {
+	p.writeComments(2, int(step.Location.Line))
+	def := strings.TrimSpace(step.Keyword) + " " + strings.TrimLeftFunc(step.Text, unicode.IsSpace)
+	if pad := width - utf8.RuneCountInString(strings.TrimSpace(step.Keyword)); pad > 0 {
+		def = strings.Repeat(" ", pad) + def
+	}
+	p.checkWidth(2, int(step.Location.Line), def)
+	if strings.TrimSpace(step.Text) == "" {
+		// the keyword of a step is only recognized with a space after it,
+		// which write would trim
+		p.result.WriteString(p.indent(2) + def + "\n")
+		p.blanks = 0
+	} else {
+		p.write(2, "%s", def)
+	}
+	switch {
+	case step.DocString != nil:
+		p.docString(step.DocString)
+	case step.DataTable != nil:
+		return p.table(step.DataTable)
+	}
+	return nil
+}
@@ -0,0 +1,545 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// corpus returns the feature files in testdata.
+func corpus(t testing.TB) []string {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.feature"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// TestFormat formats each feature file in testdata and compares the result
+// with the .golden file next to it. Run with -update to rewrite them.
+func TestFormat(t *testing.T) {
+	for _, file := range corpus(t) {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".feature"), func(t *testing.T) {
+			src, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := FormatBytes(src, DefaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := strings.TrimSuffix(file, ".feature") + ".golden"
+			if *update {
+				if err := ioutil.WriteFile(golden, out, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, want) {
+				t.Errorf("got:\n%s\nwant:\n%s", out, want)
+			}
+			again, err := FormatBytes(out, DefaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again, out) {
+				t.Errorf("formatting again changed the output:\n%s", again)
+			}
+		})
+	}
+}
+
+// formatString formats src with cfg.
+func formatString(t *testing.T, src string, cfg Config) string {
+	t.Helper()
+	out, err := FormatBytes([]byte(src), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestAlignKeywordsWithBullets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AlignKeywords = true
+	src := "Feature: a\n  Scenario: b\n    Given x\n    * y\n    Then z\n    *   w  v\n    And q\n"
+	want := `Feature: a
+
+  Scenario: b
+    Given x
+        * y
+     Then z
+        * w  v
+      And q
+`
+	if out := formatString(t, src, cfg); out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestIndentContinuations(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IndentContinuations = true
+	src := "Feature: a\n  Scenario: b\n    Given x\n    And y\n    And z\n    When w\n    But v\n    Then u\n    * t\n"
+	want := `Feature: a
+
+  Scenario: b
+    Given x
+      And y
+      And z
+    When w
+     But v
+    Then u
+       * t
+`
+	if out := formatString(t, src, cfg); out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestSortExamples(t *testing.T) {
+	src := "Feature: a\n  Scenario Outline: b\n    Given <n> <s>\n    Examples:\n      | n | s |\n      | 9 | b |\n      | 10 | c |\n      | 2 | a |\n"
+	for _, tt := range []struct {
+		column int
+		want   string
+	}{
+		// cells compare as strings
+		{0, "| n  | s |\n      | 10 | c |\n      | 2  | a |\n      | 9  | b |\n"},
+		{1, "| n  | s |\n      | 2  | a |\n      | 9  | b |\n      | 10 | c |\n"},
+	} {
+		cfg := DefaultConfig()
+		cfg.SortExamples = true
+		cfg.SortExamplesColumn = tt.column
+		want := "Feature: a\n\n  Scenario Outline: b\n    Given <n> <s>\n\n    Examples:\n      " + tt.want
+		if out := formatString(t, src, cfg); out != want {
+			t.Errorf("column %d: got:\n%s\nwant:\n%s", tt.column, out, want)
+		}
+	}
+}
+
+func TestTagCase(t *testing.T) {
+	for _, tt := range []struct {
+		tag, mode, want string
+	}{
+		{"@WIP", "lower", "@wip"},
+		{"@WIP", "preserve", "@WIP"},
+		{"@wip", "upper", "@WIP"},
+		{"@Jira(PROJ-1)", "lower", "@jira(PROJ-1)"},
+		{"@jira(proj-1)", "upper", "@JIRA(proj-1)"},
+		{"@Owner(Ann(B))", "lower", "@owner(Ann(B))"},
+	} {
+		if got := tagCase(tt.tag, tt.mode); got != tt.want {
+			t.Errorf("tagCase(%q, %q) = %q, want %q", tt.tag, tt.mode, got, tt.want)
+		}
+	}
+	cfg := DefaultConfig()
+	cfg.TagCase = "lower"
+	src := "@WIP @jira(PROJ-1)\nFeature: a\n"
+	if out, want := formatString(t, src, cfg), "@wip @jira(PROJ-1)\nFeature: a\n"; out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestTagWrap(t *testing.T) {
+	src := "@api @smoke @slow @nightly\nFeature: a\n  @wip @jira(PROJ-1) @flaky @linux\n  Scenario: b\n    Given c\n"
+	for _, tt := range []struct {
+		wrap string
+		want string
+	}{
+		{"inline", `@api @smoke @slow @nightly
+Feature: a
+
+  @wip @jira(PROJ-1) @flaky @linux
+  Scenario: b
+`},
+		{"one-per-line", `@api
+@smoke
+@slow
+@nightly
+Feature: a
+
+  @wip
+  @jira(PROJ-1)
+  @flaky
+  @linux
+  Scenario: b
+`},
+		// the indentation counts towards the width
+		{"width", `@api @smoke @slow
+@nightly
+Feature: a
+
+  @wip @jira(PROJ-1)
+  @flaky @linux
+  Scenario: b
+`},
+	} {
+		cfg := DefaultConfig()
+		cfg.TagWrap = tt.wrap
+		cfg.TagWidth = 20
+		want := tt.want + "    Given c\n"
+		if out := formatString(t, src, cfg); out != want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.wrap, out, want)
+		}
+	}
+}
+
+func TestFinalNewline(t *testing.T) {
+	const want = "Feature: a\n\n  Scenario: b\n    Given c"
+	for _, src := range []string{
+		"Feature: a\n  Scenario: b\n    Given c",
+		"Feature: a\n  Scenario: b\n    Given c\n",
+		"Feature: a\n  Scenario: b\n    Given c\n\n\n",
+	} {
+		for _, none := range []bool{false, true} {
+			cfg := DefaultConfig()
+			cfg.NoFinalNewline = none
+			want := want
+			if !none {
+				want += "\n"
+			}
+			if out := formatString(t, src, cfg); out != want {
+				t.Errorf("NoFinalNewline %v: %q formats to %q, want %q", none, src, out, want)
+			}
+		}
+	}
+}
+
+func TestSortTags(t *testing.T) {
+	src := "@smoke @API @wip\nFeature: a\n  @slow @Critical @fast\n  Scenario: b\n    Given c\n"
+	for _, tt := range []struct {
+		sort bool
+		want string
+	}{
+		{false, "@smoke @API @wip\nFeature: a\n\n  @slow @Critical @fast\n"},
+		{true, "@API @smoke @wip\nFeature: a\n\n  @Critical @fast @slow\n"},
+	} {
+		cfg := DefaultConfig()
+		cfg.SortTags = tt.sort
+		want := tt.want + "  Scenario: b\n    Given c\n"
+		if out := formatString(t, src, cfg); out != want {
+			t.Errorf("SortTags %v: got:\n%s\nwant:\n%s", tt.sort, out, want)
+		}
+	}
+}
+
+func TestBOM(t *testing.T) {
+	const doc = "Feature: a\n\n  Scenario: b\n    Given c\n"
+	withBOM := "\ufeff" + doc
+	for _, tt := range []struct {
+		mode, src, want string
+	}{
+		{"preserve", withBOM, withBOM},
+		{"preserve", doc, doc},
+		{"strip", withBOM, doc},
+		{"strip", doc, doc},
+		{"add", withBOM, withBOM},
+		{"add", doc, withBOM},
+	} {
+		cfg := DefaultConfig()
+		cfg.BOM = tt.mode
+		if out := formatString(t, tt.src, cfg); out != tt.want {
+			t.Errorf("BOM %s: %q formats to %q, want %q", tt.mode, tt.src, out, tt.want)
+		}
+	}
+}
+
+func TestMaxWidth(t *testing.T) {
+	src := "Feature: a\n  this description line is much too long to fit\n  Scenario: b\n    Given a very long step text that goes on and on\n      | a long cell | another long cell |\n"
+	want := `Feature: a
+  this description line is
+  much too long to fit
+
+  Scenario: b
+    Given a very long step text that goes on and on
+      | a long cell | another long cell |
+`
+	var warnings []string
+	cfg := DefaultConfig()
+	cfg.MaxWidth = 30
+	cfg.Warn = func(line int, msg string) {
+		warnings = append(warnings, fmt.Sprintf("%d: %s", line, msg))
+	}
+	if out := formatString(t, src, cfg); out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	wantWarnings := []string{
+		"4: line is 51 columns wide, more than 30",
+		"5: line is 41 columns wide, more than 30",
+	}
+	if strings.Join(warnings, "\n") != strings.Join(wantWarnings, "\n") {
+		t.Errorf("got warnings:\n%s\nwant:\n%s", strings.Join(warnings, "\n"), strings.Join(wantWarnings, "\n"))
+	}
+}
+
+func TestNormalizeUnicode(t *testing.T) {
+	// e followed by a combining acute accent, and the precomposed é
+	const nfd, nfc = "cafe\u0301", "caf\u00e9"
+	const doc = "Feature: %[1]s\n\n  Scenario: b\n    Given %[1]s\n    \"\"\"\n    %[1]s\n    \"\"\"\n"
+	for _, tt := range []struct {
+		normalize bool
+		want      string
+	}{
+		{false, nfd},
+		{true, nfc},
+	} {
+		cfg := DefaultConfig()
+		cfg.NormalizeUnicode = tt.normalize
+		want := fmt.Sprintf(doc, tt.want)
+		if out := formatString(t, fmt.Sprintf(doc, nfd), cfg); out != want {
+			t.Errorf("NormalizeUnicode %v: got %q, want %q", tt.normalize, out, want)
+		}
+	}
+}
+
+// TestZeroConfig checks that the zero Config formats like DefaultConfig.
+func TestZeroConfig(t *testing.T) {
+	for _, file := range corpus(t) {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := FormatBytes(src, DefaultConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := FormatBytes(src, Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", file, got, want)
+		}
+	}
+}
+
+// FuzzFormat checks that formatting is idempotent: a formatted document
+// parses and formats to itself.
+func FuzzFormat(f *testing.F) {
+	for _, file := range corpus(f) {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(src)
+	}
+	f.Fuzz(func(t *testing.T, src []byte) {
+		out, err := FormatBytes(src, DefaultConfig())
+		if err != nil {
+			return
+		}
+		again, err := FormatBytes(out, DefaultConfig())
+		if err != nil {
+			t.Fatalf("formatted document does not parse: %v\n%s", err, out)
+		}
+		if !bytes.Equal(again, out) {
+			t.Errorf("formatting again changed the output:\n%q\n%q", out, again)
+		}
+	})
+}
+
+// TestDescriptionBlankLines checks that descriptions do not change the
+// blank lines around the nodes they belong to.
+func TestDescriptionBlankLines(t *testing.T) {
+	const doc = "Feature: a\n%s  Background:\n%s    Given b\n\n  Rule: c\n%s    Scenario: d\n%s      Given e\n"
+	described := fmt.Sprintf(doc, "  feature text\n", "    background text\n", "  rule text\n", "    scenario text\n")
+	undescribed := fmt.Sprintf(doc, "", "", "", "")
+	want, err := FormatBytes([]byte(undescribed), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := FormatBytes([]byte(described), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.HasSuffix(line, " text") {
+			lines = append(lines, line)
+		}
+	}
+	if got := strings.Join(lines, "\n"); got != string(want) {
+		t.Errorf("without the descriptions got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestTrimNames checks that the names of all nodes are trimmed the same
+// way. The parser trims names read from the source, so the test pads them
+// in a Transform.
+func TestTrimNames(t *testing.T) {
+	src := "Feature: f\n  Background: b\n    Given x\n  Scenario Outline: o\n    Given <x>\n    Examples: e\n      | x |\n      | 1 |\n  Rule: r\n    Scenario: s\n      Given x\n"
+	pad := func(name *string) { *name = " \t" + *name + ".  " }
+	for _, trim := range []bool{false, true} {
+		cfg := DefaultConfig()
+		cfg.TrimNames = trim
+		cfg.Transform = func(doc *messages.GherkinDocument) error {
+			pad(&doc.Feature.Name)
+			for _, c := range doc.Feature.Children {
+				switch {
+				case c.Background != nil:
+					pad(&c.Background.Name)
+				case c.Scenario != nil:
+					pad(&c.Scenario.Name)
+					for _, ex := range c.Scenario.Examples {
+						pad(&ex.Name)
+					}
+				case c.Rule != nil:
+					pad(&c.Rule.Name)
+					for _, rc := range c.Rule.Children {
+						pad(&rc.Scenario.Name)
+					}
+				}
+			}
+			return nil
+		}
+		out, err := FormatBytes([]byte(src), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := 0
+		for _, line := range strings.Split(string(out), "\n") {
+			i := strings.Index(line, ": ")
+			if i < 0 {
+				continue
+			}
+			names++
+			name := line[i+2:]
+			want := strings.TrimSpace(name)
+			if trim {
+				want = strings.TrimSuffix(want, ".")
+			}
+			if name != want || !trim && !strings.HasSuffix(name, ".") {
+				t.Errorf("TrimNames %v: got %q", trim, line)
+			}
+		}
+		if names != 6 {
+			t.Errorf("TrimNames %v: got %d names, want 6:\n%s", trim, names, out)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Header = "# Copyright 2021 The Authors\n# Licensed under the MIT license\n# See LICENSE\n"
+	src := "# language: de\nFunktionalität: a\n  Szenario: b\n    Angenommen c\n"
+	want := cfg.Header + "\n# language: de\nFunktionalität: a\n\n  Szenario: b\n    Angenommen c\n"
+	out, err := FormatBytes([]byte(src), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out, want)
+	}
+	again, err := FormatBytes(out, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != want {
+		t.Errorf("formatting again got:\n%s\nwant:\n%s", again, want)
+	}
+}
+
+func TestTransformRemovesTag(t *testing.T) {
+	src := "@wip @smoke\nFeature: a\n\n  @wip\n  Scenario: b\n    Given c\n"
+	cfg := DefaultConfig()
+	cfg.Transform = func(doc *messages.GherkinDocument) error {
+		drop := func(tags []*messages.Tag) []*messages.Tag {
+			var kept []*messages.Tag
+			for _, tag := range tags {
+				if tag.Name != "@wip" {
+					kept = append(kept, tag)
+				}
+			}
+			return kept
+		}
+		doc.Feature.Tags = drop(doc.Feature.Tags)
+		for _, c := range doc.Feature.Children {
+			if c.Scenario != nil {
+				c.Scenario.Tags = drop(c.Scenario.Tags)
+			}
+		}
+		return nil
+	}
+	out, err := FormatBytes([]byte(src), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "@smoke\nFeature: a\n\n  Scenario: b\n    Given c\n"; string(out) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// cancelWriter cancels a context on its first write.
+type cancelWriter struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(b []byte) (int, error) {
+	w.cancel()
+	return w.Buffer.Write(b)
+}
+
+func TestFormatContextCancel(t *testing.T) {
+	src := outline(10)
+	src = append(src, "  Scenario: a\n    Given b\n  Scenario: c\n    Given d\n"...)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &cancelWriter{cancel: cancel}
+	err := FormatContext(ctx, bytes.NewReader(src), w, DefaultConfig())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if w.Len() == 0 || strings.Contains(w.String(), "Scenario: c") {
+		t.Errorf("formatting did not stop after the first scenario:\n%s", w.String())
+	}
+}
+
+// outline returns a scenario outline whose examples table has n rows.
+func outline(n int) []byte {
+	var b bytes.Buffer
+	b.WriteString("Feature: f\n  Scenario Outline: o\n    Given <a> and <b>\n    Examples:\n      | a | b | c |\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "| %d | row %d | %s |\n", i, i, strings.Repeat("x", i%17))
+	}
+	return b.Bytes()
+}
+
+// BenchmarkFormatExamples compares streaming a large examples table to w
+// with FormatBytes, which holds the whole output in memory. Both allocate
+// the parsed document, so the difference is the size of the output.
+func BenchmarkFormatExamples(b *testing.B) {
+	src := outline(5000)
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(src)))
+		for i := 0; i < b.N; i++ {
+			if err := Format(bytes.NewReader(src), ioutil.Discard, DefaultConfig()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(src)))
+		for i := 0; i < b.N; i++ {
+			if _, err := FormatBytes(src, DefaultConfig()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package format
+
+// gherkin5Keywords holds, for each language whose keyword lists Gherkin 6
+// reordered, the keyword that came first before. Mostly this is the
+// scenario keyword, which gave up its place to a translation of Example.
+// The formatter keeps writing these, so that moving to a newer parser
+// does not rewrite every Scenario of a document. In Georgian the old
+// keyword is gone and the new spelling of Scenario is used instead.
+var gherkin5Keywords = map[string]map[string]string{
+	"af":      {"scenario": "Situasie"},
+	"am":      {"scenario": "Սցենար"},
+	"an":      {"scenario": "Caso"},
+	"ar":      {"scenario": "سيناريو"},
+	"ast":     {"scenario": "Casu"},
+	"az":      {"scenario": "Ssenari"},
+	"bg":      {"scenario": "Сценарий"},
+	"bs":      {"scenario": "Scenariju"},
+	"ca":      {"scenario": "Escenari"},
+	"cs":      {"scenario": "Scénář"},
+	"cy-GB":   {"scenario": "Scenario"},
+	"da":      {"scenario": "Scenarie"},
+	"de":      {"scenario": "Szenario"},
+	"el":      {"scenario": "Σενάριο"},
+	"em":      {"scenario": "📕"},
+	"en":      {"scenario": "Scenario"},
+	"eo":      {"scenario": "Scenaro"},
+	"es":      {"scenario": "Escenario"},
+	"et":      {"scenario": "Stsenaarium", "scenarioOutline": "Raamstsenaarium"},
+	"fa":      {"scenario": "سناریو"},
+	"fr":      {"scenario": "Scénario"},
+	"ga":      {"scenario": "Cás"},
+	"gj":      {"scenario": "સ્થિતિ"},
+	"gl":      {"scenario": "Escenario"},
+	"he":      {"scenario": "תרחיש"},
+	"hr":      {"scenario": "Scenarij"},
+	"hu":      {"scenario": "Forgatókönyv"},
+	"it":      {"scenario": "Scenario"},
+	"ka":      {"scenario": "სცენარი"},
+	"kn":      {"scenario": "ಕಥಾಸಾರಾಂಶ"},
+	"lt":      {"scenario": "Scenarijus"},
+	"lu":      {"scenario": "Szenario"},
+	"lv":      {"scenario": "Scenārijs"},
+	"mk-Cyrl": {"scenario": "Сценарио"},
+	"nl":      {"scenario": "Scenario"},
+	"no":      {"scenario": "Scenario"},
+	"pa":      {"scenario": "ਪਟਕਥਾ"},
+	"pl":      {"scenario": "Scenariusz"},
+	"pt":      {"scenario": "Cenário"},
+	"ro":      {"scenario": "Scenariu"},
+	"ru":      {"scenario": "Сценарий"},
+	"sk":      {"scenario": "Scenár"},
+	"sl":      {"scenario": "Scenarij"},
+	"sr-Cyrl": {"scenario": "Сценарио"},
+	"ta":      {"scenario": "காட்சி"},
+	"tr":      {"scenario": "Senaryo"},
+	"uk":      {"scenario": "Сценарій"},
+}
@@ -0,0 +1,86 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+
+	gherkin "github.com/cucumber/gherkin/go/v26"
+)
+
+var languagePattern = regexp.MustCompile(`^\s*#\s*language\s*:\s*([a-zA-Z\-_]+)\s*$`)
+
+// splitFeatures splits src into one segment per feature. The tags and
+// comments right above a feature line belong to its segment. Each segment
+// is padded with the empty lines of the segments before it, so that the
+// parser reports the lines of src for it.
+func splitFeatures(src string, dialects gherkin.DialectProvider) []string {
+	lines := strings.Split(src, "\n")
+	var starts []int
+	language := gherkin.DefaultDialect
+	delimiter := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if delimiter != "" {
+			if strings.HasPrefix(trimmed, delimiter) {
+				delimiter = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, "```") {
+			delimiter = trimmed[:3]
+			continue
+		}
+		if m := languagePattern.FindStringSubmatch(line); m != nil {
+			language = m[1]
+			continue
+		}
+		if !isFeatureLine(trimmed, dialects.GetDialect(language)) {
+			continue
+		}
+		start := i
+		for start > 0 {
+			above := strings.TrimSpace(lines[start-1])
+			if above != "" && above[0] != '@' && above[0] != '#' {
+				break
+			}
+			start--
+		}
+		for start < i && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+		if len(starts) > 0 {
+			starts = append(starts, start)
+		} else {
+			// anything above the first feature stays with it
+			starts = append(starts, 0)
+		}
+		// a language comment only applies to the feature below it
+		language = gherkin.DefaultDialect
+	}
+	if len(starts) < 2 {
+		return []string{src}
+	}
+	segments := make([]string, len(starts))
+	for k, start := range starts {
+		end := len(lines)
+		if k+1 < len(starts) {
+			end = starts[k+1]
+		}
+		segments[k] = strings.Repeat("\n", start) + strings.Join(lines[start:end], "\n")
+	}
+	return segments
+}
+
+// isFeatureLine reports whether line, without surrounding whitespace,
+// starts a feature in dialect.
+func isFeatureLine(line string, dialect *gherkin.Dialect) bool {
+	if dialect == nil {
+		return false
+	}
+	for _, keyword := range dialect.FeatureKeywords() {
+		if strings.HasPrefix(line, keyword+":") {
+			return true
+		}
+	}
+	return false
+}
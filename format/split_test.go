@@ -0,0 +1,67 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+const twoFeatures = `# file header
+
+@first
+Feature: one
+  Scenario: a
+    Given x
+
+# about two
+@second @slow
+Feature: two
+  Scenario: b
+    Given y
+`
+
+func TestSplitFeatures(t *testing.T) {
+	segments := splitFeatures(twoFeatures, builtin)
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2:\n%q", len(segments), segments)
+	}
+	want := []string{
+		// anything above the first feature stays with it
+		"# file header\n\n@first\nFeature: one\n  Scenario: a\n    Given x\n",
+		"# about two\n@second @slow\nFeature: two\n  Scenario: b\n    Given y\n",
+	}
+	for i, segment := range segments {
+		if got := strings.TrimLeft(segment, "\n"); got != want[i] {
+			t.Errorf("segment %d: got:\n%s\nwant:\n%s", i, got, want[i])
+		}
+	}
+	// the padding keeps the line numbers of the source
+	if n := strings.Count(segments[1], "\n") - strings.Count(want[1], "\n"); n != 7 {
+		t.Errorf("second segment starts on line %d, want 8", n+1)
+	}
+}
+
+func TestFormatMultipleFeatures(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MultipleFeatures = true
+	want := `# file header
+@first
+Feature: one
+
+  Scenario: a
+    Given x
+
+# about two
+@second @slow
+Feature: two
+
+  Scenario: b
+    Given y
+`
+	out := formatString(t, twoFeatures, cfg)
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	if again := formatString(t, out, cfg); again != out {
+		t.Errorf("formatting again changed the output:\n%s", again)
+	}
+}
@@ -0,0 +1,217 @@
+package format
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	messages "github.com/cucumber/messages/go/v21"
+	"golang.org/x/text/width"
+)
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// displayWidth returns the number of terminal columns s occupies: combining
+// marks take no space and East Asian wide characters take two.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case width.LookupRune(r).Kind() == width.EastAsianWide,
+			width.LookupRune(r).Kind() == width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// columnAlign right-aligns column j if all of its cells in body are
+// numbers, and left-aligns it otherwise.
+func columnAlign(body []*messages.TableRow, j int) string {
+	if len(body) == 0 {
+		return "left"
+	}
+	for _, row := range body {
+		if _, err := strconv.ParseFloat(row.Cells[j].Value, 64); err != nil {
+			return "left"
+		}
+	}
+	return "right"
+}
+
+// escapeCell escapes a cell value so that the parser reads it back
+// unchanged on a single line. Pipes and newlines are always escaped; a
+// backslash only where the parser would otherwise combine it with the
+// following character, which may be the closing pipe.
+func escapeCell(val string) string {
+	var b strings.Builder
+	for i := 0; i < len(val); i++ {
+		switch c := val[i]; {
+		case c == '|':
+			b.WriteString(`\|`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\\' && (i+1 == len(val) || strings.IndexByte(`\|n`, val[i+1]) >= 0):
+			b.WriteString(`\\`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+var separatorCell = regexp.MustCompile(`^:?-+:?$`)
+
+// isSeparator reports whether row is a markdown header separator such as
+// | --- | ---: | below header, in the shape separator writes it: one cell
+// per column, each at least three characters wide. Shorter rows of dashes,
+// such as | - | -- |, are table data.
+func isSeparator(header, row *messages.TableRow) bool {
+	if len(row.Cells) != len(header.Cells) {
+		return false
+	}
+	for _, cell := range row.Cells {
+		if len(cell.Value) < 3 || !separatorCell.MatchString(cell.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// separator returns a markdown separator cell of the given width.
+func separator(mode string, width int) string {
+	switch mode {
+	case "right":
+		return strings.Repeat("-", width-1) + ":"
+	case "center":
+		return ":" + strings.Repeat("-", width-2) + ":"
+	case "none":
+		return "---"
+	default:
+		return ":" + strings.Repeat("-", width-1)
+	}
+}
+
+// separatorAlign returns the alignment a markdown separator cell asks for,
+// or "" for a cell without colons.
+func separatorAlign(cell string) string {
+	switch left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":"); {
+	case left && right:
+		return "center"
+	case right:
+		return "right"
+	case left:
+		return "left"
+	}
+	return ""
+}
+
+// flushRows is the number of table rows written between flushes.
+const flushRows = 256
+
+func (p *printer) table(v *messages.DataTable) error {
+	rows := v.Rows
+	markdown := p.cfg.TableStyle == "markdown"
+	var hints []string
+	if markdown && len(rows) > 1 && isSeparator(rows[0], rows[1]) {
+		// drop the separator of an earlier run, it is written again below
+		// with the alignment it asks for
+		for _, cell := range rows[1].Cells {
+			hints = append(hints, separatorAlign(cell.Value))
+		}
+		rows = append([]*messages.TableRow{rows[0]}, rows[2:]...)
+	}
+
+	// escape every cell once, the values are needed for both passes
+	cells := make([][]string, len(rows))
+	cellWidths := make([][]int, len(rows))
+	widths := make([]int, len(rows[0].Cells))
+	for i := range rows {
+		cells[i] = make([]string, len(rows[i].Cells))
+		cellWidths[i] = make([]int, len(rows[i].Cells))
+		for j, col := range rows[i].Cells {
+			cells[i][j] = escapeCell(col.Value)
+			cellWidths[i][j] = displayWidth(cells[i][j])
+			widths[j] = max(widths[j], cellWidths[i][j])
+		}
+	}
+	if markdown {
+		for j := range widths {
+			widths[j] = max(widths[j], 3)
+		}
+	}
+	modes := make([]string, len(widths))
+	for j := range modes {
+		modes[j] = p.cfg.Align
+		if p.cfg.Align == "auto" {
+			modes[j] = columnAlign(rows[1:], j)
+		}
+		if j < len(hints) && hints[j] != "" {
+			modes[j] = hints[j]
+		}
+	}
+	pad := strings.Repeat(" ", p.cfg.CellPadding)
+	size := 1
+	for _, w := range widths {
+		size += w + 2*len(pad) + 1
+	}
+	var row strings.Builder
+	for i := range rows {
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+		if i > 0 && i%flushRows == 0 {
+			// the widths are known, so long tables need not be held whole
+			if err := p.flush(false); err != nil {
+				return err
+			}
+		}
+		p.writeComments(3, int(rows[i].Location.Line))
+		row.Reset()
+		row.Grow(size)
+		row.WriteString("|")
+		for j, val := range cells[i] {
+			n := widths[j] - cellWidths[i][j]
+			row.WriteString(pad)
+			switch modes[j] {
+			case "none":
+				row.WriteString(val)
+			case "right":
+				row.WriteString(strings.Repeat(" ", n))
+				row.WriteString(val)
+			case "center":
+				row.WriteString(strings.Repeat(" ", n/2))
+				row.WriteString(val)
+				row.WriteString(strings.Repeat(" ", n-n/2))
+			default:
+				row.WriteString(val)
+				row.WriteString(strings.Repeat(" ", n))
+			}
+			row.WriteString(pad)
+			row.WriteString("|")
+		}
+		p.checkWidth(3, int(rows[i].Location.Line), row.String())
+		p.write(3, "%s", row.String())
+		if markdown && i == 0 {
+			row.Reset()
+			row.WriteString("|")
+			for j := range widths {
+				row.WriteString(pad)
+				row.WriteString(separator(modes[j], widths[j]))
+				row.WriteString(pad)
+				row.WriteString("|")
+			}
+			p.write(3, "%s", row.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want int
+	}{
+		{"abc", 3},
+		{"caf\u00e9", 4},
+		{"cafe\u0301", 4},
+		{"日本語", 6},
+		{"ｶﾀｶﾅ", 4},
+		{"😀", 2},
+		{"a\u200bb", 2},
+	} {
+		if got := displayWidth(tt.s); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+// pipeColumns returns the display columns of the pipes of a table line.
+func pipeColumns(line string) []int {
+	var cols []int
+	for i, r := range line {
+		if r == '|' {
+			cols = append(cols, displayWidth(line[:i]))
+		}
+	}
+	return cols
+}
+
+func TestTableAlignsWideCells(t *testing.T) {
+	src := `Feature: a
+  Scenario: b
+    Given c
+      | name | x |
+      | café | 1 |
+      | cafe` + "\u0301" + ` | 2 |
+      | 日本語 | 3 |
+      | 😀 | 4 |
+`
+	for _, align := range []string{"left", "right", "center"} {
+		cfg := DefaultConfig()
+		cfg.Align = align
+		out, err := FormatBytes([]byte(src), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []int
+		for _, line := range strings.Split(string(out), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "|") {
+				continue
+			}
+			cols := pipeColumns(line)
+			if want == nil {
+				want = cols
+				continue
+			}
+			if !reflect.DeepEqual(cols, want) {
+				t.Errorf("align %s: pipes of %q at %v, want %v\n%s", align, line, cols, want, out)
+			}
+		}
+	}
+}
+
+func TestCellPadding(t *testing.T) {
+	src := "Feature: a\n  Scenario: b\n    Given c\n      | a | bb |\n      | ccc | d |\n"
+	for _, tt := range []struct {
+		padding int
+		want    string
+	}{
+		{-1, "|a  |bb|\n      |ccc|d |"},
+		{0, "| a   | bb |\n      | ccc | d  |"},
+		{1, "| a   | bb |\n      | ccc | d  |"},
+		{2, "|  a    |  bb  |\n      |  ccc  |  d   |"},
+	} {
+		cfg := DefaultConfig()
+		cfg.CellPadding = tt.padding
+		out, err := FormatBytes([]byte(src), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "Feature: a\n\n  Scenario: b\n    Given c\n      " + tt.want + "\n"
+		if string(out) != want {
+			t.Errorf("padding %d: got\n%s\nwant\n%s", tt.padding, out, want)
+		}
+	}
+}
+
+// TestAlignCenter checks that centered cells get half of the padding on the
+// left, rounded down, and the rest on the right.
+func TestAlignCenter(t *testing.T) {
+	src := "Feature: a\n  Scenario: b\n    Given c\n      | abcd | abcde |\n      | x | y |\n      | xy | yz |\n"
+	want := `      | abcd | abcde |
+      |  x   |   y   |
+      |  xy  |  yz   |
+`
+	cfg := DefaultConfig()
+	cfg.Align = "center"
+	if out := formatString(t, src, cfg); !strings.HasSuffix(out, want) {
+		t.Errorf("got:\n%s\nwant it to end with:\n%s", out, want)
+	}
+}
+
+func TestAlignNone(t *testing.T) {
+	src := "Feature: a\n  Scenario: b\n    Given c\n      | abcd |   abcde |\n      |x| y |\n"
+	want := "      | abcd | abcde |\n      | x | y |\n"
+	cfg := DefaultConfig()
+	cfg.Align = "none"
+	if out := formatString(t, src, cfg); !strings.HasSuffix(out, want) {
+		t.Errorf("got:\n%s\nwant it to end with:\n%s", out, want)
+	}
+}
+
+// BenchmarkTable formats a data table of 10000 rows with escaped and wide
+// cells.
+func BenchmarkTable(b *testing.B) {
+	var buf bytes.Buffer
+	buf.WriteString("Feature: f\n  Scenario: s\n    Given a table\n")
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&buf, "| %d | a\\|b %d | 日本 | %s |\n", i, i%97, strings.Repeat("x", i%23))
+	}
+	src := buf.Bytes()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		if err := Format(bytes.NewReader(src), ioutil.Discard, DefaultConfig()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// markdown formats src with the markdown table style.
+func markdown(t *testing.T, src string) string {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.TableStyle = "markdown"
+	out, err := FormatBytes([]byte(src), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := FormatBytes(out, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, out) {
+		t.Errorf("formatting again changed the output:\n%s\n%s", out, again)
+	}
+	return string(out)
+}
+
+func TestMarkdownKeepsDashRows(t *testing.T) {
+	src := "Feature: a\n  Scenario Outline: b\n    Given <a> <b>\n    Examples:\n      | a | b |\n      | - | -- |\n      | x | y |\n"
+	want := `    Examples:
+      | a   | b   |
+      | :-- | :-- |
+      | -   | --  |
+      | x   | y   |
+`
+	if out := markdown(t, src); !strings.HasSuffix(out, want) {
+		t.Errorf("got:\n%s\nwant it to end with:\n%s", out, want)
+	}
+}
+
+func TestMarkdownSeparatorHints(t *testing.T) {
+	src := "Feature: a\n  Scenario: b\n    Given c\n      | name | count | x |\n      | :--- | ---: | :-: |\n      | apples | 3 | y |\n      | kiwis | 12 | zz |\n"
+	want := `      | name   | count |  x  |
+      | :----- | ----: | :-: |
+      | apples |     3 |  y  |
+      | kiwis  |    12 | zz  |
+`
+	if out := markdown(t, src); !strings.HasSuffix(out, want) {
+		t.Errorf("got:\n%s\nwant it to end with:\n%s", out, want)
+	}
+}
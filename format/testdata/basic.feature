@@ -0,0 +1,37 @@
+# top comment
+@smoke @wip
+Feature: Login   
+  Some description
+
+  line two
+
+  Background:
+    Given a user
+
+  # comment above scenario
+  @s1
+  Scenario: Log in
+    Given I am   on "the  page"
+    # step comment
+    When I log in
+    * bullet
+    Then I see
+      | name | value |
+      | a\|b | 1     |
+      # row comment
+      | c    | 22    |
+    And docstring
+      """json
+      {"b": 1, "a": [1,2]}
+      """
+
+  Scenario Outline: outline <x>
+    Given <x>
+
+    @ex
+    Examples: first
+      | x |
+      | 1 |
+
+# end
+# of feature
@@ -0,0 +1,22 @@
+Feature: Blank lines
+  A description
+
+
+
+  with a gap
+
+
+
+
+  Scenario: first
+    Given a
+
+
+
+
+  Scenario: second
+    Given b
+
+
+
+
@@ -0,0 +1,32 @@
+# top comment
+@smoke
+Feature: Login
+  Some description
+  # after desc
+
+  # before bg
+  Background:
+    # before step
+    Given a user
+
+  # a comment
+  @fast
+  Scenario: ok
+    Given foo  bar
+    # between
+      # weird indent
+    When x
+      | a | b |
+      # in table
+      | 1 | 22 |
+    Then y
+
+  Scenario Outline: out
+    Given <x>
+
+    # above examples
+    @ex1
+    Examples:
+      | x |
+      | 1 |
+# trailing
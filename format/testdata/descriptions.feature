@@ -0,0 +1,26 @@
+@a
+@b @c
+Feature: Edge
+  First line
+
+  second para
+  # comment after desc
+
+  Scenario: with desc
+    desc line
+
+    more desc
+    # c1
+    Given x
+      """
+        indented
+      back
+      """
+
+  Scenario Outline: o
+    Given <a>
+
+    Examples: ex
+      ex desc
+      | a |
+      | 1 |
@@ -0,0 +1,13 @@
+Feature: Deploy
+  Scenario: run the deploy script
+    # a gherkin comment
+    Given the script
+      """sh
+      #!/bin/sh
+      # stop on errors
+      set -e
+        # indented comment
+      deploy --env prod # trailing comment
+      """
+    # another gherkin comment
+    When it runs
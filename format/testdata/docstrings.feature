@@ -0,0 +1,26 @@
+Feature: d
+  Scenario: s
+    Given json
+      """json
+      {"z": 10000000000000001, "a": 1.000000000000000000001, "h": "<&>"}
+      """
+    And xml
+      ```xml
+      <root a="1"><child>text</child><empty></empty><!-- c --></root>
+      ```
+    And collide
+      ```
+      some
+      """
+      ```
+    And nested
+      """
+      def f():
+          return 1
+        # not a comment
+      """
+    # gherkin-fmt: raw
+    And raw
+      """json
+      {"keep":   1}
+      """
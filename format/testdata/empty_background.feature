@@ -0,0 +1,8 @@
+Feature: stepless background
+  Background:
+  Scenario: first
+    Given a
+  Rule: r
+    Background: also empty
+    Scenario: second
+      Then b
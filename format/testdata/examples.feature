@@ -0,0 +1,20 @@
+Feature: tricky
+      Indented description
+    second line
+
+  Background: bg
+    bg description
+    Given x
+
+  Scenario Outline: o
+    o description
+    Given <a>
+    Examples:
+    Examples: header only
+      | a |
+    @t1 @t2
+    Examples: tagged
+      ex description
+      | a |
+      | 2 |
+      | 1 |
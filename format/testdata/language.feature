@@ -0,0 +1,15 @@
+# language: de
+Funktionalität: Test
+  Grundlage:
+    Angenommen x
+  Szenario: y
+    Angenommen a
+    Und b
+    Aber c
+    Wenn d
+    Dann e
+  Szenariogrundriss: z
+    Angenommen <x>
+    Beispiele:
+      | x |
+      | 1 |
@@ -0,0 +1 @@
+Feature: Work in progress
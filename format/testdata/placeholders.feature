@@ -0,0 +1,9 @@
+Feature: placeholders
+  Scenario Outline: padded and plain placeholders
+    Given a user named < name >
+    And   a <role> with <  spaces  > kept
+    Then the greeting is "<greeting>"
+    Examples:
+      | < name > | <role> |<  spaces  >| greeting |
+      | alice | admin | x | hi < name > |
+      | bob   | guest | y | hello <role> |
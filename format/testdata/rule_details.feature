@@ -0,0 +1,20 @@
+Feature: Accounts
+  @billing   @slow
+  Rule: Payments must be approved
+      Approval is done by a second person.
+
+      Unapproved payments are kept for a week.
+
+    Background: a payment
+      Given a payment of 10 EUR
+
+    # approved
+    @happy
+    Example: approved payment
+      When it is approved
+      Then it is sent
+
+  # the next rule
+  Rule:
+    Scenario: unnamed rule
+      Given nothing
@@ -0,0 +1,25 @@
+Feature: Shop
+Background:
+  Given a shop
+
+Scenario: browse without rules
+  When I browse
+  Then I see products
+
+Rule: Members get a discount
+Background:
+  Given I am a member
+Scenario: discount
+  When I buy a book
+  Then I pay less
+Scenario Outline: discount on <item>
+  When I buy a <item>
+  Then I pay less
+  Examples:
+  | item |
+  | pen  |
+
+Rule: Guests pay full price
+Scenario: no discount
+  When I buy a book
+  Then I pay the full price
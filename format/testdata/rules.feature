@@ -0,0 +1,7 @@
+Feature: r
+  Rule: one
+    Scenario: a
+      Given x
+  Rule: two
+    Scenario: b
+      Given y
@@ -0,0 +1,12 @@
+Feature: x
+  Scenario Outline: a
+    Given <b>
+      | a | b |
+  # first
+      | 1 | 2 |
+        # second
+      | 3 | 4 |
+    Examples:
+    | b |
+    # ex comment
+    | 1 |
@@ -0,0 +1,6 @@
+@jira(PROJ-42)
+Feature: Tag arguments
+
+  @timeout(5000) @jira(PROJ-1) @owner(team:payments,backup:ops)
+  Scenario: tags keep their arguments
+    Given a slow step
@@ -0,0 +1,61 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkWidth reports text, written at indent for source line line, to the
+// Warn function if it is wider than MaxWidth.
+func (p *printer) checkWidth(indent, line int, text string) {
+	if p.cfg.MaxWidth <= 0 || p.cfg.Warn == nil {
+		return
+	}
+	if w := p.column(indent) + displayWidth(text); w > p.cfg.MaxWidth {
+		p.cfg.Warn(line, fmt.Sprintf("line is %d columns wide, more than %d", w, p.cfg.MaxWidth))
+	}
+}
+
+// wrap splits a description line written at indent into lines that fit
+// into MaxWidth, keeping its leading whitespace. Words longer than the
+// width stay on their own line, and no line may start with something the
+// parser would read as a keyword, tag, comment or table.
+func (p *printer) wrap(indent int, line string) []string {
+	if p.cfg.MaxWidth <= 0 || p.column(indent)+displayWidth(line) <= p.cfg.MaxWidth {
+		return []string{line}
+	}
+	lead := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	width := p.cfg.MaxWidth - p.column(indent) - displayWidth(lead)
+	var lines []string
+	cur := ""
+	for _, word := range strings.Fields(line) {
+		if cur != "" && displayWidth(cur+" "+word) > width && p.safeStart(word) {
+			lines = append(lines, lead+cur)
+			cur = word
+			continue
+		}
+		if cur != "" {
+			cur += " "
+		}
+		cur += word
+	}
+	return append(lines, lead+cur)
+}
+
+// safeStart reports whether a description line may start with word, which
+// is the case unless the line would no longer be read as description.
+func (p *printer) safeStart(word string) bool {
+	if strings.ContainsAny(word[:1], "#@|") || strings.HasPrefix(word, `"""`) || strings.HasPrefix(word, "```") {
+		return false
+	}
+	for _, keywords := range p.dialect.Keywords {
+		for _, keyword := range keywords {
+			// only the first word of the line is known here, so keywords
+			// made of several words are matched by their first word
+			if first := strings.Fields(keyword); len(first) > 0 && strings.HasPrefix(word, first[0]) {
+				return false
+			}
+		}
+	}
+	return true
+}
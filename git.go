@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// modifiedFiles returns the files with one of exts that git reports as
+// added, modified or untracked in the repository of the working directory,
+// relative to it. Deleted files are left out.
+func modifiedFiles(exts, ignore []string) ([]string, error) {
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSpace(string(top))
+	status, err := gitOutput("status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	entries := strings.Split(string(status), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		xy, path := entry[:2], entry[3:]
+		if xy[0] == 'R' || xy[0] == 'C' {
+			// the source of a rename or copy follows
+			i++
+		}
+		if strings.ContainsRune(xy, 'D') {
+			continue
+		}
+		if !hasExt(path, exts) || ignored(path, ignore) {
+			continue
+		}
+		file := filepath.Join(root, filepath.FromSlash(path))
+		if rel, err := filepath.Rel(wd, file); err == nil {
+			file = rel
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// gitOutput runs git with args and returns its standard output. The error
+// includes what git printed to standard error.
+func gitOutput(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
@@ -1,5 +1,15 @@
 module github.com/juliusmh/gherkin-fmt
 
-go 1.17
+go 1.19
 
-require github.com/cucumber/gherkin-go v5.1.0+incompatible
+require (
+	github.com/cucumber/gherkin/go/v26 v26.2.0
+	github.com/cucumber/messages/go/v21 v21.0.1
+	github.com/fsnotify/fsnotify v1.5.1
+	golang.org/x/text v0.3.7
+)
+
+require (
+	github.com/gofrs/uuid v4.3.1+incompatible // indirect
+	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
+)
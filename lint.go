@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	messages "github.com/cucumber/messages/go/v21"
+)
+
+// warning is a problem found in a document that does not prevent it from
+// being formatted.
+type warning struct {
+	line int
+	msg  string
+}
+
+// duplicateScenarios warns about scenarios and outlines sharing a name
+// within the feature of doc, which reports cannot tell apart.
+func duplicateScenarios(doc *messages.GherkinDocument) []warning {
+	var warnings []warning
+	seen := make(map[string]int)
+	for _, v := range scenarios(doc) {
+		name, line := v.Name, int(v.Location.Line)
+		if name == "" {
+			// reported by -warn-unnamed
+			continue
+		}
+		if first, ok := seen[name]; ok {
+			warnings = append(warnings, warning{line, fmt.Sprintf("duplicate scenario name %q, first used on line %d", name, first)})
+			continue
+		}
+		seen[name] = line
+	}
+	return warnings
+}
+
+// unnamed warns about a feature, scenarios and outlines without a name.
+func unnamed(doc *messages.GherkinDocument) []warning {
+	if doc.Feature == nil {
+		return nil
+	}
+	var warnings []warning
+	if doc.Feature.Name == "" {
+		warnings = append(warnings, warning{int(doc.Feature.Location.Line), "feature has no name"})
+	}
+	for _, v := range scenarios(doc) {
+		switch {
+		case v.Name != "":
+		case len(v.Examples) > 0:
+			warnings = append(warnings, warning{int(v.Location.Line), "scenario outline has no name"})
+		default:
+			warnings = append(warnings, warning{int(v.Location.Line), "scenario has no name"})
+		}
+	}
+	return warnings
+}
+
+// scenarios returns the scenarios and outlines of doc, those inside rules
+// included, in source order.
+func scenarios(doc *messages.GherkinDocument) []*messages.Scenario {
+	if doc.Feature == nil {
+		return nil
+	}
+	var list []*messages.Scenario
+	for _, c := range doc.Feature.Children {
+		if c.Scenario != nil {
+			list = append(list, c.Scenario)
+		}
+		if c.Rule != nil {
+			for _, rc := range c.Rule.Children {
+				if rc.Scenario != nil {
+					list = append(list, rc.Scenario)
+				}
+			}
+		}
+	}
+	return list
+}
@@ -1,195 +1,625 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
-	"strconv"
+	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/cucumber/gherkin-go"
+	gherkin "github.com/cucumber/gherkin/go/v26"
+	messages "github.com/cucumber/messages/go/v21"
+	"github.com/juliusmh/gherkin-fmt/format"
 )
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "devel"
 
 type config struct {
-	dry    bool
-	indent int
-	align  string
+	format.Config
+	write   bool
+	check   bool
+	list    bool
+	diff    bool
+	output  string
+	json    bool
+	formats *configLoader
+
+	stdinFilename string
+	exts          []string
+
+	warnDuplicates bool
+	warnUnnamed    bool
+	strict         bool
 }
 
-func fmtFile(file string, cfg *config) error {
+// errNotFeature is returned for files skipped because of their extension.
+var errNotFeature = errors.New("not a feature file, see -ext")
+
+// errDirectory is returned for directories given without -r.
+var errDirectory = errors.New("is a directory, use -r")
+
+// fmtFile formats a single file and reports whether its content changed.
+// Without -w, the result or its diff is written to out. Warnings are
+// written to stderr.
+func fmtFile(file string, cfg *config, out, stderr io.Writer) (bool, error) {
 	stat, err := os.Stat(file)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if stat.IsDir() {
+		return false, errDirectory
+	}
+	if !hasExt(file, cfg.exts) {
+		return false, errNotFeature
+	}
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false, fmt.Errorf("could not open %q: %+v", file, err)
+	}
+	fcfg, err := cfg.formats.load(file)
+	if err != nil {
+		return false, err
+	}
+	if cfg.json {
+		return false, dumpJSON(bytes.NewReader(src), out, fcfg)
+	}
+	if err := lint(file, src, cfg, fcfg, stderr); err != nil {
+		return false, err
+	}
+	warnings := 0
+	fc := *fcfg
+	fc.Warn = func(line int, msg string) {
+		warnings++
+		fmt.Fprintf(stderr, "%s:%d: %s\n", file, line, msg)
+	}
+	var result bytes.Buffer
+	if err := format.Format(bytes.NewReader(src), &result, fc); err != nil {
+		return false, err
+	}
+	if cfg.strict && warnings > 0 {
+		return false, fmt.Errorf("%d warnings with -strict", warnings)
+	}
+	changed := !bytes.Equal(src, result.Bytes())
+
+	if cfg.check || (cfg.list && !cfg.write) {
+		return changed, nil
+	}
+
+	if cfg.diff {
+		out.Write(unifiedDiff(file, src, result.Bytes()))
+		return changed, nil
+	}
+
+	if cfg.output != "" {
+		return changed, writeFile(cfg.output, result.Bytes(), stat.Mode().Perm())
+	}
+
+	if !cfg.write {
+		out.Write(result.Bytes())
+		return changed, nil
+	}
+	if !changed {
+		// keep the modification time of formatted files
+		return false, nil
+	}
+	return changed, writeFile(file, result.Bytes(), stat.Mode().Perm())
+}
+
+// lint prints warnings about src, parsed with fcfg, to out. With -strict,
+// any warning is returned as an error and the file is left alone.
+func lint(file string, src []byte, cfg *config, fcfg *format.Config, out io.Writer) error {
+	if !cfg.warnDuplicates && !cfg.warnUnnamed {
 		return nil
 	}
-	f, err := os.Open(file)
+	docs, err := parse(bytes.NewReader(src), fcfg)
+	if err != nil {
+		return err
+	}
+	var warnings []warning
+	for _, doc := range docs {
+		if cfg.warnDuplicates {
+			warnings = append(warnings, duplicateScenarios(doc)...)
+		}
+		if cfg.warnUnnamed {
+			warnings = append(warnings, unnamed(doc)...)
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].line < warnings[j].line
+	})
+	for _, w := range warnings {
+		fmt.Fprintf(out, "%s:%d: %s\n", file, w.line, w.msg)
+	}
+	if cfg.strict && len(warnings) > 0 {
+		return fmt.Errorf("%d warnings with -strict", len(warnings))
+	}
+	return nil
+}
+
+// writeFile replaces file by writing data to a temporary file in the same
+// directory and renaming it over the original, so an interrupted write
+// never leaves a truncated file behind.
+func writeFile(file string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(file); err == nil {
+		file = resolved
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// readDialects reads a -dialect-file.
+func readDialects(name string) (gherkin.DialectProvider, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return format.ReadDialects(f)
+}
+
+// readHeader reads the header to put above every file from name. Its lines
+// must be comments so that the output stays valid gherkin.
+func readHeader(name string) (string, error) {
+	src, err := ioutil.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	for i, line := range strings.Split(string(src), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return "", fmt.Errorf("line %d is not a comment", i+1)
+		}
+	}
+	return string(src), nil
+}
+
+// parse parses a document from r with the dialects of cfg. With
+// MultipleFeatures, it returns one document per feature.
+func parse(r io.Reader, cfg *format.Config) ([]*messages.GherkinDocument, error) {
+	if cfg.MultipleFeatures {
+		return format.ParseFeatures(r, cfg.Dialects)
+	}
+	gherkinDocument, err := format.ParseWithDialects(r, cfg.Dialects)
+	if err != nil {
+		return nil, err
+	}
+	return []*messages.GherkinDocument{gherkinDocument}, nil
+}
+
+// dumpJSON writes the parsed document as indented JSON to out. A file with
+// several features, parsed with MultipleFeatures, is written as an array of
+// documents.
+func dumpJSON(r io.Reader, out io.Writer, cfg *format.Config) error {
+	docs, err := parse(r, cfg)
 	if err != nil {
-		return fmt.Errorf("could not open %q: %+v", file, err)
+		return err
+	}
+	var v interface{} = docs
+	if len(docs) == 1 {
+		v = docs[0]
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
 	}
-	gherkinDocument, err := gherkin.ParseGherkinDocument(f)
+	_, err = fmt.Fprintf(out, "%s\n", b)
+	return err
+}
+
+// standardInput is the name input read from stdin is reported under.
+const standardInput = "<standard input>"
+
+// fmtStdin formats stdin and reports whether its formatting differs. The
+// result, or its diff with -d, is written to stdout unless -check or -list
+// is given. Warnings are reported under the -stdin-filename path, if any.
+func fmtStdin(cfg *config) (bool, error) {
+	fcfg := &cfg.Config
+	name := standardInput
+	if cfg.stdinFilename != "" {
+		name = cfg.stdinFilename
+		var err error
+		if fcfg, err = cfg.formats.load(cfg.stdinFilename); err != nil {
+			return false, err
+		}
+	}
+	if cfg.json {
+		return false, dumpJSON(os.Stdin, os.Stdout, fcfg)
+	}
+	src, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
-		f.Close()
-		return fmt.Errorf("could not open %q: %+v", file, err)
+		return false, err
 	}
-	f.Close()
-	if gherkinDocument.Feature == nil {
-		return fmt.Errorf("empty feature body")
+	if err := lint(name, src, cfg, fcfg, os.Stderr); err != nil {
+		return false, err
+	}
+	warnings := 0
+	fc := *fcfg
+	fc.Warn = func(line int, msg string) {
+		warnings++
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", name, line, msg)
 	}
 	var result bytes.Buffer
-	write := func(indent int, f string, args ...interface{}) {
-		add := strings.Repeat(" ", indent*cfg.indent)
-		lines := strings.Split(fmt.Sprintf(f, args...), "\n")
-		for _, line := range lines {
-			result.WriteString(add + line + "\n")
+	if err := format.Format(bytes.NewReader(src), &result, fc); err != nil {
+		return false, err
+	}
+	if cfg.strict && warnings > 0 {
+		return false, fmt.Errorf("%d warnings with -strict", warnings)
+	}
+	changed := !bytes.Equal(src, result.Bytes())
+	if cfg.check || cfg.list {
+		return changed, nil
+	}
+	if cfg.diff {
+		_, err = os.Stdout.Write(unifiedDiff(standardInput, src, result.Bytes()))
+		return changed, err
+	}
+	if cfg.output != "" {
+		return changed, writeFile(cfg.output, result.Bytes(), 0644)
+	}
+	_, err = os.Stdout.Write(result.Bytes())
+	return changed, err
+}
+
+// printError reports why name was skipped. Parse errors are printed with
+// their position, one per line.
+func printError(name string, err error) {
+	var errs format.ParseErrors
+	if errors.As(err, &errs) {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s:%v\n", name, err)
 		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "skip %s: %+v\n", name, err)
+}
+
+// exitCode returns the exit status for err: 2 for syntax errors and 1 for
+// anything else.
+func exitCode(err error) int {
+	var errs format.ParseErrors
+	if errors.As(err, &errs) {
+		return 2
+	}
+	return 1
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
 	}
-	write(0, "Feature: %s", gherkinDocument.Feature.Name)
-	write(0, gherkinDocument.Feature.Description)
-	write(0, "")
+	return b
+}
 
-	for _, c := range gherkinDocument.Feature.Children {
+// stringList is a flag that may be given multiple times.
+type stringList []string
 
-		fmtString := func(v *gherkin.DocString) {
-			defer write(2, "\"\"\"")
-			write(2, "\"\"\"")
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
 
-			var a interface{}
-			err := json.Unmarshal([]byte(v.Content), &a)
-			if err != nil {
-				write(0, v.Content)
-				return
-			}
-			var buf bytes.Buffer
-			e := json.NewEncoder(&buf)
-			e.SetEscapeHTML(false)
-			e.SetIndent("", strings.Repeat(" ", cfg.indent))
-			if err = e.Encode(a); err != nil {
-				write(0, v.Content)
-				return
-			}
-			write(2, strings.TrimSpace(buf.String()))
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// ignored reports whether path, relative to the walked directory, matches
+// one of the patterns. A pattern matches either the base name or the whole
+// slash-separated path.
+func ignored(path string, patterns []string) bool {
+	path = filepath.ToSlash(path)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+			return true
 		}
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
 
-		fmtTable := func(v *gherkin.DataTable) {
-			align := make([]int, len(v.Rows[0].Cells))
-			sanitize := func(val string) string {
-				val = strings.Replace(val, "|", "\\|", -1)
-				return val
-			}
-			for i := range v.Rows {
-				for j, col := range v.Rows[i].Cells {
-					align[j] = max(align[j], len(sanitize(col.Value)))
-				}
-			}
-			format := "|"
-			for _, a := range align {
-				switch cfg.align {
-				case "right":
-					format += " %" + strconv.Itoa(a) + "s |"
-				case "left":
-					format += " %-" + strconv.Itoa(a) + "s |"
-				}
-			}
-			for i := range v.Rows {
-				args := make([]interface{}, len(v.Rows[i].Cells))
-				for j, col := range v.Rows[i].Cells {
-					args[j] = sanitize(col.Value)
-				}
-				write(3, format, args...)
-			}
+// hasExt reports whether the extension of path is one of exts.
+func hasExt(path string, exts []string) bool {
+	for _, ext := range exts {
+		if filepath.Ext(path) == ext {
+			return true
 		}
+	}
+	return false
+}
 
-		var steps []*gherkin.Step
-		var examples []*gherkin.DataTable
-		switch v := c.(type) {
-		case *gherkin.Background:
-			if v.Name != "" {
-				write(1, "Background: %s", strings.TrimSpace(v.Name))
-			} else {
-				write(1, "Background:")
+// featureFiles expands the directories in args into the files with one of
+// exts below them when recursive is set, skipping paths matching ignore.
+// Other arguments are kept as they are.
+func featureFiles(args []string, recursive bool, exts, ignore []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		stat, err := os.Stat(arg)
+		if !recursive || err != nil || !stat.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
 			}
-			steps = v.Steps
-		case *gherkin.Scenario:
-			write(1, "Scenario: %s", strings.TrimSpace(v.Name))
-			steps = v.Steps
-		case *gherkin.ScenarioOutline:
-			write(1, "Scenario Outline: %s", strings.TrimSpace(v.Name))
-			steps = v.Steps
-			examples = make([]*gherkin.DataTable, len(v.Examples))
-			for i, ex := range v.Examples {
-				examples[i] = &gherkin.DataTable{
-					Rows: append([]*gherkin.TableRow{ex.TableHeader}, ex.TableBody...),
+			if rel, _ := filepath.Rel(arg, path); rel != "." && ignored(rel, ignore) {
+				if d.IsDir() {
+					return filepath.SkipDir
 				}
+				return nil
 			}
-		default:
-			return fmt.Errorf("unhandled feature children: %T", v)
-		}
-
-		for _, step := range steps {
-			def := strings.Replace(step.Keyword+" "+step.Text, "  ", " ", -1)
-			write(2, "%s", def)
-			if step.Argument == nil {
-				continue
-			}
-			switch v := step.Argument.(type) {
-			case *gherkin.DocString:
-				fmtString(v)
-				continue
-			case *gherkin.DataTable:
-				fmtTable(v)
-				continue
-			default:
-				return fmt.Errorf("unsupported step argument: %T\n", v)
+			if !d.IsDir() && hasExt(path, exts) {
+				files = append(files, path)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
+	}
+	return files, nil
+}
 
-		for _, ex := range examples {
-			write(0, "")
-			write(2, "Examples:")
-			fmtTable(ex)
+// fileList reads newline-separated paths from name, or from stdin if name
+// is "-". Blank lines are ignored.
+func fileList(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
 		}
-
-		write(0, "")
+		defer f.Close()
+		r = f
 	}
-
-	if cfg.dry {
-		fmt.Println(strings.TrimSpace(result.String()))
-		return nil
+	var files []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			files = append(files, line)
+		}
 	}
+	return files, s.Err()
+}
 
-	return ioutil.WriteFile(file, bytes.TrimSpace(result.Bytes()), 666)
+// outcome is the result of formatting one file. Its output and warnings
+// are buffered so that concurrent workers do not interleave them.
+type outcome struct {
+	changed bool
+	err     error
+	out     bytes.Buffer
+	stderr  bytes.Buffer
+	done    chan struct{}
+}
+
+// fmtFiles formats files with n concurrent workers. report is called for
+// every file in the order of files, as soon as its outcome is available.
+func fmtFiles(files []string, cfg *config, n int, report func(name string, o *outcome)) {
+	outcomes := make([]*outcome, len(files))
+	for i := range outcomes {
+		outcomes[i] = &outcome{done: make(chan struct{})}
+	}
+	jobs := make(chan int)
+	go func() {
+		for i := range files {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	if n < 1 {
+		n = 1
+	}
+	for w := 0; w < n; w++ {
+		go func() {
+			for i := range jobs {
+				o := outcomes[i]
+				o.changed, o.err = fmtFile(files[i], cfg, &o.out, &o.stderr)
+				close(o.done)
+			}
+		}()
+	}
+	for i, o := range outcomes {
+		<-o.done
+		report(files[i], o)
+		outcomes[i] = nil
+	}
 }
 
 func main() {
+	cfg := &config{}
+	var ignore stringList
 	var (
-		dry    = flag.Bool("dry", false, "run in dry mode")
-		indent = flag.Int("indent", 2, "amount of whitespaces for indentation")
-		align  = flag.String("align", "left", "align tables left|right")
+		write     = flag.Bool("w", false, "write the result to the source file instead of stdout")
+		dry       = flag.Bool("dry", false, "deprecated: printing to stdout is the default without -w")
+		check     = flag.Bool("check", false, "list files whose formatting differs and exit with status 1")
+		list      = flag.Bool("list", false, "only print the names of files whose formatting differs")
+		diff      = flag.Bool("d", false, "print a unified diff instead of rewriting files")
+		recursive = flag.Bool("r", false, "format all feature files in directories recursively")
+		jobs      = flag.Int("j", 1, "number of files to format concurrently")
+		output    = flag.String("o", "", "write the formatted file to this path instead of the input")
+		dumpAST   = flag.Bool("json", false, "print the parsed document as JSON instead of formatting")
+		quiet     = flag.Bool("quiet", false, "do not print the names of processed files, only errors")
+		summary   = flag.Bool("summary", false, "print a tally of formatted, unchanged and failed files at the end")
+		stdinName = flag.String("stdin-filename", "", "path used to find the .gherkinfmt file for input read from stdin")
+		filesFrom = flag.String("files-from", "", "read the files to format from this file, one per line, or - for stdin")
+		exts      = flag.String("ext", ".feature", "comma-separated extensions of the files to format")
+		watchMode = flag.Bool("watch", false, "format files again whenever they are saved, implies -w")
+		dialect   = flag.String("dialect-file", "", "JSON file with keyword overrides for the gherkin dialects")
+		header    = flag.String("header-file", "", "file with comment lines to put at the top of every file, such as a license")
+		modified  = flag.Bool("modified", false, "format the feature files git reports as changed or untracked")
+		printVer  = flag.Bool("version", false, "print the version and exit")
+		warnDups  = flag.Bool("warn-duplicate-scenarios", false, "warn about scenarios sharing a name within a feature")
+		warnNames = flag.Bool("warn-unnamed", false, "warn about features and scenarios without a name")
+		strict    = flag.Bool("strict", false, "treat warnings as errors")
 	)
+	flag.BoolVar(quiet, "q", false, "shorthand for -quiet")
+	flag.Var(&ignore, "ignore", "skip paths matching this glob when recursing (repeatable)")
+	formatFlags(flag.CommandLine, &cfg.Config)
 	flag.Parse()
+	if *printVer {
+		fmt.Println("gherkin-fmt", version)
+		return
+	}
 
-	for i := 0; i < flag.NArg(); i++ {
-		name := flag.Arg(i)
-		if err := fmtFile(name, &config{
-			dry:    *dry,
-			indent: *indent,
-			align:  *align,
-		}); err != nil {
-			fmt.Printf("skip %s: %+v\n", name, err)
-			continue
+	if err := checkIndent(&cfg.Config); err != nil {
+		fmt.Fprintf(os.Stderr, "-%v\n", err)
+		os.Exit(1)
+	}
+	checkAlign(&cfg.Config, "-align")
+	if *dialect != "" {
+		dialects, err := readDialects(*dialect)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *dialect, err)
+			os.Exit(1)
+		}
+		cfg.Dialects = dialects
+	}
+	if *header != "" {
+		h, err := readHeader(*header)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *header, err)
+			os.Exit(1)
+		}
+		cfg.Header = h
+	}
+	if *dry {
+		fmt.Fprintln(os.Stderr, "-dry is deprecated: files are only rewritten with -w")
+	}
+	cfg.write = *write && !*dry
+	cfg.check = *check
+	cfg.list = *list
+	cfg.diff = *diff
+	cfg.output = *output
+	cfg.json = *dumpAST
+	cfg.stdinFilename = *stdinName
+	cfg.exts = strings.Split(*exts, ",")
+	cfg.warnDuplicates = *warnDups
+	cfg.warnUnnamed = *warnNames
+	cfg.strict = *strict
+	cfg.formats = newConfigLoader(cfg.Config)
+
+	args := flag.Args()
+	if *filesFrom != "" {
+		list, err := fileList(*filesFrom)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		args = append(args, list...)
+	}
+	if *modified {
+		list, err := modifiedFiles(cfg.exts, ignore)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		args = append(args, list...)
+	}
+	if *filesFrom == "" && !*modified && (flag.NArg() == 0 || (flag.NArg() == 1 && flag.Arg(0) == "-")) {
+		changed, err := fmtStdin(cfg)
+		if err != nil {
+			printError(standardInput, err)
+			os.Exit(exitCode(err))
+		}
+		if changed && (cfg.check || cfg.list) {
+			if !*quiet {
+				fmt.Println(standardInput)
+			}
+			if cfg.check {
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	if *watchMode {
+		cfg.write = true
+		if err := watch(args, cfg, *recursive, ignore); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	files, err := featureFiles(args, *recursive, cfg.exts, ignore)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if cfg.output != "" && len(files) != 1 {
+		fmt.Fprintf(os.Stderr, "-o requires exactly one input file, got %d\n", len(files))
+		os.Exit(1)
+	}
+
+	status := 0
+	var nchanged, nunchanged, nfailed int
+	fmtFiles(files, cfg, *jobs, func(name string, o *outcome) {
+		os.Stdout.Write(o.out.Bytes())
+		os.Stderr.Write(o.stderr.Bytes())
+		if o.err == errNotFeature || o.err == errDirectory {
+			printError(name, o.err)
+			return
+		}
+		if o.err != nil {
+			nfailed++
+			status = max(status, exitCode(o.err))
+			printError(name, o.err)
+			return
+		}
+		if o.changed {
+			nchanged++
+		} else {
+			nunchanged++
+		}
+		if cfg.check || cfg.list {
+			if o.changed {
+				if cfg.check {
+					status = max(status, 1)
+				}
+				if !*quiet {
+					fmt.Println(name)
+				}
+			}
+			return
+		}
+		if cfg.diff || cfg.json || *quiet || (!cfg.write && cfg.output == "") {
+			// the result itself went to stdout
+			return
 		}
 		fmt.Println(name)
+	})
+	if *summary {
+		verb := "formatted"
+		if cfg.check || cfg.diff || (!cfg.write && cfg.output == "") {
+			// the files themselves were left alone
+			verb = "would reformat"
+		}
+		fmt.Fprintf(os.Stderr, "%s %d, unchanged %d, errors %d\n", verb, nchanged, nunchanged, nfailed)
 	}
+	os.Exit(status)
 }
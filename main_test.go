@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/juliusmh/gherkin-fmt/format"
+)
+
+// binary is the gherkin-fmt binary built by TestMain.
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "gherkin-fmt")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(dir, "gherkin-fmt")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "go build: %v\n%s", err, out)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// testConfig returns the configuration of a run without flags.
+func testConfig() *config {
+	cfg := &config{Config: format.DefaultConfig(), exts: []string{".feature"}}
+	cfg.formats = newConfigLoader(cfg.Config)
+	return cfg
+}
+
+// writeFeature writes src to a feature file in a new directory.
+func writeFeature(t *testing.T, src string, perm os.FileMode) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "a.feature")
+	if err := ioutil.WriteFile(file, []byte(src), perm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(file, perm); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestWriteKeepsMode(t *testing.T) {
+	file := writeFeature(t, "Feature: a\n Scenario: b\n", 0600)
+	cfg := testConfig()
+	cfg.write = true
+	changed, err := fmtFile(file, cfg, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("file was not rewritten")
+	}
+	stat, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := stat.Mode().Perm(); perm != 0600 {
+		t.Errorf("mode is %v, want %v", perm, os.FileMode(0600))
+	}
+}
+
+func TestWriteKeepsModTimeOfFormattedFile(t *testing.T) {
+	file := writeFeature(t, "Feature: a\n\n  Scenario: b\n    Given c\n", 0644)
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.write = true
+	changed, err := fmtFile(file, cfg, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("formatted file reported as changed")
+	}
+	stat, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stat.ModTime().Equal(mtime) {
+		t.Errorf("modification time is %v, want %v", stat.ModTime(), mtime)
+	}
+}
+
+func TestDirectoryWithoutRecursion(t *testing.T) {
+	dir := filepath.Dir(writeFeature(t, "Feature: a\n Scenario: b\n", 0644))
+	out, err := exec.Command(binary, "-w", dir).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if want := "skip " + dir + ": is a directory, use -r\n"; string(out) != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w"}, "formatted 1, unchanged 0, errors 0\n"},
+		{[]string{"-check"}, "would reformat 1, unchanged 0, errors 0\n"},
+		{[]string{"-list"}, "would reformat 1, unchanged 0, errors 0\n"},
+		{[]string{"-d"}, "would reformat 1, unchanged 0, errors 0\n"},
+		{nil, "would reformat 1, unchanged 0, errors 0\n"},
+	} {
+		file := writeFeature(t, "Feature: a\n Scenario: b\n", 0644)
+		cmd := exec.Command(binary, append(append(tt.args, "-q", "-summary"), file)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		cmd.Run()
+		if stderr.String() != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.args, stderr.String(), tt.want)
+		}
+	}
+}
+
+func TestLintMultipleFeatures(t *testing.T) {
+	src := "Feature: a\n  Scenario: b\n  Scenario: b\n\nFeature:\n  Scenario: b\n"
+	file := writeFeature(t, src, 0644)
+	cfg := testConfig()
+	cfg.MultipleFeatures = true
+	cfg.warnDuplicates = true
+	cfg.warnUnnamed = true
+	var stderr bytes.Buffer
+	if err := lint(file, []byte(src), cfg, &cfg.Config, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	want := file + ":3: duplicate scenario name \"b\", first used on line 2\n" +
+		file + ":5: feature has no name\n"
+	if stderr.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stderr.String(), want)
+	}
+}
+
+func TestStdinWarnings(t *testing.T) {
+	src := "Feature: a\n\n  Scenario: b\n    Given c\n\n  Scenario: b\n    Given d\n"
+	for _, tt := range []struct {
+		args []string
+		want string
+		code int
+	}{
+		{[]string{"-warn-duplicate-scenarios"}, standardInput + ":6: duplicate scenario name \"b\", first used on line 3\n", 0},
+		{[]string{"-warn-duplicate-scenarios", "-stdin-filename", "a.feature"}, "a.feature:6: duplicate scenario name \"b\", first used on line 3\n", 0},
+		{[]string{"-warn-duplicate-scenarios", "-strict"}, standardInput + ":6: duplicate scenario name \"b\", first used on line 3\n" +
+			"skip " + standardInput + ": 1 warnings with -strict\n", 1},
+		{[]string{"-max-width", "10"}, standardInput + ":4: line is 11 columns wide, more than 10\n" +
+			standardInput + ":7: line is 11 columns wide, more than 10\n", 0},
+	} {
+		cmd := exec.Command(binary, tt.args...)
+		cmd.Stdin = strings.NewReader(src)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		code := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code || stderr.String() != tt.want {
+			t.Errorf("%v: exit status %d and stderr %q, want %d and %q", tt.args, code, stderr.String(), tt.code, tt.want)
+		}
+		if tt.code == 0 && stdout.String() != src {
+			t.Errorf("%v: got output %q, want %q", tt.args, stdout.String(), src)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		src  string
+		args []string
+		want int
+	}{
+		{"formatted", "Feature: a\n\n  Scenario: b\n    Given c\n", []string{"-check"}, 0},
+		{"unformatted", "Feature: a\n Scenario: b\n  Given c\n", []string{"-check"}, 1},
+		{"rewritten", "Feature: a\n Scenario: b\n  Given c\n", []string{"-w"}, 0},
+		{"syntax error", "Given a\nFeature: b\n", []string{"-check"}, 2},
+		{"invalid option", "Feature: a\n", []string{"-indent", "0"}, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeFeature(t, tt.src, 0644)
+			cmd := exec.Command(binary, append(tt.args, file)...)
+			out, err := cmd.CombinedOutput()
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.want {
+				t.Errorf("exit status %d, want %d\n%s", code, tt.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounce is how long a file has to stay untouched after an event before
+// it is formatted, so that a single save triggers a single run.
+const debounce = 100 * time.Millisecond
+
+// watch formats the feature files named by args, or below them if they are
+// directories, whenever they are saved. It only returns on error.
+func watch(args []string, cfg *config, recursive bool, ignore []string) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	// files are watched on their own, dirs with all feature files in them
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	add := func(dir string) error {
+		dirs[filepath.Clean(dir)] = true
+		return w.Add(dir)
+	}
+	for _, arg := range args {
+		stat, err := os.Stat(arg)
+		if err != nil {
+			return err
+		}
+		if !stat.IsDir() {
+			files[filepath.Clean(arg)] = true
+			if err := w.Add(filepath.Dir(arg)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !recursive {
+			if err := add(arg); err != nil {
+				return err
+			}
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			if rel, _ := filepath.Rel(arg, path); rel != "." && ignored(rel, ignore) {
+				return filepath.SkipDir
+			}
+			return add(path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	timers := make(map[string]*time.Timer)
+	ready := make(chan string)
+	for {
+		select {
+		case ev := <-w.Events:
+			name := filepath.Clean(ev.Name)
+			if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if stat, err := os.Stat(name); err == nil && stat.IsDir() {
+				if recursive && ev.Op&fsnotify.Create != 0 && !ignored(name, ignore) {
+					add(name)
+				}
+				continue
+			}
+			if !files[name] && (!dirs[filepath.Dir(name)] || !hasExt(name, cfg.exts) || ignored(name, ignore)) {
+				continue
+			}
+			if t, ok := timers[name]; ok {
+				t.Stop()
+			}
+			timers[name] = time.AfterFunc(debounce, func() { ready <- name })
+		case name := <-ready:
+			delete(timers, name)
+			watchFile(name, cfg)
+		case err := <-w.Errors:
+			return err
+		}
+	}
+}
+
+// watchFile formats a file that was saved. Writing the result triggers
+// another event, which ends there as the file is then unchanged.
+func watchFile(name string, cfg *config) {
+	changed, err := fmtFile(name, cfg, os.Stdout, os.Stderr)
+	if err != nil {
+		printError(name, err)
+		return
+	}
+	if changed {
+		fmt.Println("formatted", name)
+	}
+}